@@ -1,6 +1,13 @@
 package middlewarebuilder
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type (
 	Factory[T any] interface {
@@ -10,32 +17,151 @@ type (
 
 	// Builder builds a middleware chain with a handler as last part of the chain.
 	// Since middlewares must be added in a deterministic order, Builder is not thread-safe.
+	// Once built, Builder refuses further changes, as they wouldn't affect the chain already in use.
 	Builder[T any] struct {
-		factories Factories[T]
-		handler   *T
+		factories   Factories[T]
+		handler     *T
+		frozen      bool
+		err         error
+		profiling   bool
+		lastProfile []FactoryTiming
+		order       []string
+		groups      map[string]*bool
+		collect     bool
+	}
+
+	// FactoryTiming is time taken by a factory to create its middleware.
+	FactoryTiming struct {
+		Index    int
+		Name     string
+		Duration time.Duration
 	}
 
 	// FactoryFunc implements Factory interface as function.
 	FactoryFunc[T any] func(next T) (T, error)
+
+	// Namer is implemented by factories that have a human-readable name.
+	Namer interface {
+		Name() string
+	}
+
+	namedFactory[T any] struct {
+		Factory[T]
+		name string
+	}
+
+	// groupFactory applies its factories in order unless the group is disabled.
+	groupFactory[T any] struct {
+		name      string
+		factories Factories[T]
+		enabled   *bool
+	}
 )
 
+// Named gives a factory a name used to describe a chain.
+func Named[T any](name string, f Factory[T]) Factory[T] {
+	return namedFactory[T]{Factory: f, name: name}
+}
+
+func (n namedFactory[T]) Name() string {
+	return n.name
+}
+
+// FactoryName returns the name of a named factory or its type name otherwise.
+func FactoryName(f any) string {
+	if n, ok := f.(Namer); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", f)
+}
+
+func (g groupFactory[T]) Name() string {
+	return g.name
+}
+
+func (g groupFactory[T]) Create(next T) (T, error) {
+	if !*g.enabled {
+		return next, nil
+	}
+	return g.factories.Create(next)
+}
+
 func (f FactoryFunc[T]) Create(next T) (T, error) {
 	return f(next)
 }
 
 func (f Factories[T]) Create(handler T) (T, error) {
+	return f.create(handler, nil, false)
+}
+
+// create builds the chain, reporting time taken by every factory to observe when it's not nil.
+// When collect is true, a failed factory is skipped as if it returned next, and all failures are returned joined.
+func (f Factories[T]) create(handler T, observe func(i int, d time.Duration), collect bool) (T, error) {
 	next := handler
-	var err error
+	var errs []error
 	for i := len(f) - 1; i >= 0; i-- {
-		next, err = f[i].Create(next)
-		if err != nil {
-			return next, err
+		sT := time.Now()
+		middleware, err := f[i].Create(next)
+		if observe != nil {
+			observe(i, time.Since(sT))
+		}
+		switch {
+		case errors.Is(err, ErrIncompatibleNext):
+			err = fmt.Errorf("factory at index %d (%s) can't wrap next: %w", i, FactoryName(f[i]), err)
+		case err != nil && collect:
+			err = fmt.Errorf("factory at index %d (%s): %w", i, FactoryName(f[i]), err)
+		case err == nil && isNil(middleware):
+			err = fmt.Errorf("%w: factory at index %d", errNilMiddleware, i)
+		}
+		if err == nil {
+			next = middleware
+			continue
+		}
+		if !collect {
+			return middleware, err
+		}
+		errs = append(errs, err)
+	}
+	return next, errors.Join(errs...)
+}
+
+// isNil reports whether v is nil, including a typed nil held by an interface.
+func isNil[T any](v T) bool {
+	rv := reflect.ValueOf(&v).Elem()
+	if rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return true
 		}
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Func, reflect.Map, reflect.Slice, reflect.Chan:
+		return rv.IsNil()
 	}
-	return next, nil
+	return false
 }
 
-var errMissingHandler = errors.New("missing handler")
+// ErrIncompatibleNext is returned by factories unable to wrap next, e.g. lacking an optional interface.
+// Build reports it along with index of the factory.
+var ErrIncompatibleNext = errors.New("incompatible next")
+
+var (
+	errMissingHandler = errors.New("missing handler")
+	errNilMiddleware  = errors.New("middleware factory returned nil")
+	errFrozen         = errors.New("builder changed after build")
+	errOrderMismatch  = errors.New("unexpected middleware order")
+)
+
+// RequireInterface creates factory failing with ErrIncompatibleNext unless next implements I. It passes next through otherwise.
+func RequireInterface[T, I any]() Factory[T] {
+	name := reflect.TypeOf((*I)(nil)).Elem().String()
+	return Named[T]("Require "+name, FactoryFunc[T](func(next T) (T, error) {
+		if _, ok := any(next).(I); !ok {
+			return next, fmt.Errorf("%w: %T doesn't implement %s", ErrIncompatibleNext, next, name)
+		}
+		return next, nil
+	}))
+}
 
 func NewBuilder[T any]() *Builder[T] {
 	return &Builder[T]{}
@@ -43,21 +169,189 @@ func NewBuilder[T any]() *Builder[T] {
 
 // Add middleware factory. First added middleware is first called in a chain.
 func (b *Builder[T]) Add(middlewareFactory Factory[T]) *Builder[T] {
+	if b.rejectChange("Add") {
+		return b
+	}
 	b.factories = append(b.factories, middlewareFactory)
 	return b
 }
 
+// AddGroup adds middleware factories as a named group which can be enabled or disabled as a unit.
+// Groups are enabled by default and keep the order of their factories.
+func (b *Builder[T]) AddGroup(name string, fs ...Factory[T]) *Builder[T] {
+	if b.rejectChange("AddGroup") {
+		return b
+	}
+	b.factories = append(b.factories, groupFactory[T]{name: name, factories: fs, enabled: b.groupEnabled(name)})
+	return b
+}
+
+// EnableGroup enables or disables all groups added under a name, before or after this call.
+func (b *Builder[T]) EnableGroup(name string, on bool) {
+	if b.rejectChange("EnableGroup") {
+		return
+	}
+	*b.groupEnabled(name) = on
+}
+
+func (b *Builder[T]) groupEnabled(name string) *bool {
+	if b.groups == nil {
+		b.groups = make(map[string]*bool)
+	}
+	enabled, exists := b.groups[name]
+	if !exists {
+		enabled = new(bool)
+		*enabled = true
+		b.groups[name] = enabled
+	}
+	return enabled
+}
+
 // WithHandler sets a handler used to build a chain.
 func (b *Builder[T]) WithHandler(h T) *Builder[T] {
+	if b.rejectChange("WithHandler") {
+		return b
+	}
 	b.handler = &h
 	return b
 }
 
+// WithHandlerIf sets a handler used to build a chain only when cond is true.
+// Otherwise previously set handler is kept.
+func (b *Builder[T]) WithHandlerIf(cond bool, h T) *Builder[T] {
+	if b.rejectChange("WithHandlerIf") {
+		return b
+	}
+	if cond {
+		b.handler = &h
+	}
+	return b
+}
+
+// WithProfiling makes Build record time taken by every factory, see LastBuildProfile.
+func (b *Builder[T]) WithProfiling() *Builder[T] {
+	if b.rejectChange("WithProfiling") {
+		return b
+	}
+	b.profiling = true
+	return b
+}
+
+// LastBuildProfile returns factory timings of the last build in chain order.
+// It's empty unless profiling is enabled with WithProfiling. Factories not reached by a failed build are omitted.
+func (b *Builder[T]) LastBuildProfile() []FactoryTiming {
+	profile := make([]FactoryTiming, 0, len(b.lastProfile))
+	for _, timing := range b.lastProfile {
+		if timing.Name != "" {
+			profile = append(profile, timing)
+		}
+	}
+	return profile
+}
+
+// WithCollectErrors makes Build attempt every factory instead of stopping at the first failure.
+// Failed factories are left out of the chain and Build returns all failures joined.
+func (b *Builder[T]) WithCollectErrors() *Builder[T] {
+	if b.rejectChange("WithCollectErrors") {
+		return b
+	}
+	b.collect = true
+	return b
+}
+
+// WithOrderAssertion makes Build fail unless names of factories match expected order, see Names.
+func (b *Builder[T]) WithOrderAssertion(expected []string) *Builder[T] {
+	if b.rejectChange("WithOrderAssertion") {
+		return b
+	}
+	b.order = append([]string{}, expected...)
+	return b
+}
+
+// Names of added middleware factories in chain order.
+func (b *Builder[T]) Names() []string {
+	names := make([]string, 0, len(b.factories))
+	for _, f := range b.factories {
+		names = append(names, FactoryName(f))
+	}
+	return names
+}
+
+// Plan describes the chain Build would create without invoking any factory: names of factories
+// in chain order followed by the handler. Enabled groups are expanded as "group/factory" and disabled ones omitted.
+func (b *Builder[T]) Plan() []string {
+	var plan []string
+	for _, f := range b.factories {
+		group, isGroup := f.(groupFactory[T])
+		if !isGroup {
+			plan = append(plan, FactoryName(f))
+			continue
+		}
+		if !*group.enabled {
+			continue
+		}
+		for _, member := range group.factories {
+			plan = append(plan, group.name+"/"+FactoryName(member))
+		}
+	}
+	if b.handler != nil {
+		plan = append(plan, FactoryName(*b.handler))
+	}
+	return plan
+}
+
+// DOT describes the chain Build would create, see Plan, as a Graphviz digraph with the outermost middleware at the top.
+func (b *Builder[T]) DOT() string {
+	var dot strings.Builder
+	dot.WriteString("digraph chain {\n\trankdir=TB;\n")
+	plan := b.Plan()
+	for i, name := range plan {
+		fmt.Fprintf(&dot, "\tn%d [label=%s];\n", i, strconv.Quote(name))
+	}
+	for i := 1; i < len(plan); i++ {
+		fmt.Fprintf(&dot, "\tn%d -> n%d;\n", i-1, i)
+	}
+	dot.WriteString("}\n")
+	return dot.String()
+}
+
 // Build a chain of middlewares using middleware factories with a handler as last.
+// Changes made to the builder after a successful build are rejected and reported by subsequent builds.
 func (b *Builder[T]) Build() (T, error) {
+	var zero T
+	if b.err != nil {
+		return zero, b.err
+	}
 	if b.handler == nil {
-		var zero T
 		return zero, errMissingHandler
 	}
-	return b.factories.Create(*b.handler)
+	if b.order != nil {
+		if names := b.Names(); !reflect.DeepEqual(names, b.order) {
+			return zero, fmt.Errorf("%w: got %v, expected %v", errOrderMismatch, names, b.order)
+		}
+	}
+	var observe func(i int, d time.Duration)
+	if b.profiling {
+		b.lastProfile = make([]FactoryTiming, len(b.factories))
+		observe = func(i int, d time.Duration) {
+			b.lastProfile[i] = FactoryTiming{Index: i, Name: FactoryName(b.factories[i]), Duration: d}
+		}
+	}
+	chain, err := b.factories.create(*b.handler, observe, b.collect)
+	if err != nil {
+		return chain, err
+	}
+	b.frozen = true
+	return chain, nil
+}
+
+// rejectChange records an error for changes of a built builder.
+func (b *Builder[T]) rejectChange(method string) bool {
+	if !b.frozen {
+		return false
+	}
+	if b.err == nil {
+		b.err = fmt.Errorf("%w: %s", errFrozen, method)
+	}
+	return true
 }
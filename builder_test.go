@@ -2,7 +2,10 @@ package middlewarebuilder
 
 import (
 	"errors"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 type (
@@ -55,6 +58,36 @@ func TestBuilder_Build(t *testing.T) {
 			t.Errorf("Expected example error but got: %v", err)
 		}
 	})
+	t.Run("Should return error with index of factory returning nil", func(t *testing.T) {
+		b := &Builder[textCreator]{}
+		b.
+			Add(exampleMiddlewareFactory{ExtraText: "first"}).
+			Add(FactoryFunc[textCreator](func(next textCreator) (textCreator, error) {
+				return nil, nil
+			})).
+			Add(exampleMiddlewareFactory{ExtraText: "third"}).
+			WithHandler(exampleHandler{})
+		_, err := b.Build()
+		if !errors.Is(err, errNilMiddleware) {
+			t.Fatalf("Expected nil middleware error but got: %v", err)
+		}
+		if !strings.Contains(err.Error(), "index 1") {
+			t.Errorf("Expected factory index in error but got: %s", err)
+		}
+	})
+	t.Run("Should return error for typed nil middleware", func(t *testing.T) {
+		b := &Builder[textCreator]{}
+		b.
+			Add(FactoryFunc[textCreator](func(next textCreator) (textCreator, error) {
+				var middleware *exampleMiddleware
+				return middleware, nil
+			})).
+			WithHandler(exampleHandler{})
+		_, err := b.Build()
+		if !errors.Is(err, errNilMiddleware) {
+			t.Errorf("Expected nil middleware error but got: %v", err)
+		}
+	})
 	t.Run("Should create middlewarebuilder chain in order", func(t *testing.T) {
 		b := &Builder[textCreator]{}
 		b.
@@ -73,3 +106,313 @@ func TestBuilder_Build(t *testing.T) {
 		}
 	})
 }
+
+func TestBuilder_WithHandlerIf(t *testing.T) {
+	t.Run("Should override handler when condition is true", func(t *testing.T) {
+		chain, err := NewBuilder[textCreator]().
+			WithHandler(exampleHandler{}).
+			WithHandlerIf(true, exampleMiddleware{ExtraText: "fake", Next: exampleHandler{}}).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		out := chain.CreateText("input")
+		expected := "input: fake: handler"
+		if out != expected {
+			t.Errorf("Got '%s' but expected '%s'", out, expected)
+		}
+	})
+	t.Run("Should keep previous handler when condition is false", func(t *testing.T) {
+		chain, err := NewBuilder[textCreator]().
+			WithHandler(exampleHandler{}).
+			WithHandlerIf(false, exampleMiddleware{ExtraText: "fake", Next: exampleHandler{}}).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		out := chain.CreateText("input")
+		expected := "input: handler"
+		if out != expected {
+			t.Errorf("Got '%s' but expected '%s'", out, expected)
+		}
+	})
+	t.Run("Should leave handler missing when condition is false", func(t *testing.T) {
+		_, err := NewBuilder[textCreator]().WithHandlerIf(false, exampleHandler{}).Build()
+		if !errors.Is(err, errMissingHandler) {
+			t.Errorf("Expected missing handler error but got: %v", err)
+		}
+	})
+}
+
+func TestBuilder_frozen(t *testing.T) {
+	t.Run("Should reject changes after build", func(t *testing.T) {
+		b := NewBuilder[textCreator]().
+			Add(exampleMiddlewareFactory{ExtraText: "first"}).
+			WithHandler(exampleHandler{})
+		if _, err := b.Build(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		b.Add(exampleMiddlewareFactory{ExtraText: "second"})
+		_, err := b.Build()
+		if !errors.Is(err, errFrozen) {
+			t.Errorf("Expected frozen builder error but got: %v", err)
+		}
+		if len(b.factories) != 1 {
+			t.Errorf("Got %d factories but expected 1", len(b.factories))
+		}
+	})
+	t.Run("Should build again without changes", func(t *testing.T) {
+		b := NewBuilder[textCreator]().WithHandler(exampleHandler{})
+		_, _ = b.Build()
+		if _, err := b.Build(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should allow changes after failed build", func(t *testing.T) {
+		b := NewBuilder[textCreator]()
+		_, _ = b.Build()
+		if _, err := b.WithHandler(exampleHandler{}).Build(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+}
+
+func TestBuilder_LastBuildProfile(t *testing.T) {
+	t.Run("Should record time of every factory", func(t *testing.T) {
+		b := NewBuilder[textCreator]().
+			WithProfiling().
+			Add(Named[textCreator]("fast", exampleMiddlewareFactory{ExtraText: "fast"})).
+			Add(Named[textCreator]("slow", FactoryFunc[textCreator](func(next textCreator) (textCreator, error) {
+				time.Sleep(20 * time.Millisecond)
+				return exampleMiddleware{Next: next, ExtraText: "slow"}, nil
+			}))).
+			WithHandler(exampleHandler{})
+		if _, err := b.Build(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		profile := b.LastBuildProfile()
+		if len(profile) != 2 {
+			t.Fatalf("Got %d timings but expected 2", len(profile))
+		}
+		if profile[0].Name != "fast" || profile[1].Name != "slow" {
+			t.Errorf("Got timings %v but expected fast and slow in order", profile)
+		}
+		if profile[1].Duration < 20*time.Millisecond || profile[1].Duration <= profile[0].Duration {
+			t.Errorf("Expected slow factory to dominate profile but got %v", profile)
+		}
+	})
+	t.Run("Should be empty without profiling", func(t *testing.T) {
+		b := NewBuilder[textCreator]().Add(exampleMiddlewareFactory{}).WithHandler(exampleHandler{})
+		_, _ = b.Build()
+		if profile := b.LastBuildProfile(); len(profile) != 0 {
+			t.Errorf("Expected empty profile but got %v", profile)
+		}
+	})
+}
+
+func TestBuilder_WithOrderAssertion(t *testing.T) {
+	builder := func(expected ...string) *Builder[textCreator] {
+		return NewBuilder[textCreator]().
+			WithOrderAssertion(expected).
+			Add(Named[textCreator]("first", exampleMiddlewareFactory{ExtraText: "first"})).
+			Add(Named[textCreator]("second", exampleMiddlewareFactory{ExtraText: "second"})).
+			WithHandler(exampleHandler{})
+	}
+
+	t.Run("Should build chain in expected order", func(t *testing.T) {
+		if _, err := builder("first", "second").Build(); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should return error for unexpected order", func(t *testing.T) {
+		_, err := builder("second", "first").Build()
+		if !errors.Is(err, errOrderMismatch) {
+			t.Errorf("Expected order mismatch error but got: %v", err)
+		}
+	})
+	t.Run("Should return error for missing middleware", func(t *testing.T) {
+		_, err := builder("first", "second", "third").Build()
+		if !errors.Is(err, errOrderMismatch) {
+			t.Errorf("Expected order mismatch error but got: %v", err)
+		}
+	})
+}
+
+func TestBuilder_AddGroup(t *testing.T) {
+	builder := func() *Builder[textCreator] {
+		return NewBuilder[textCreator]().
+			Add(exampleMiddlewareFactory{ExtraText: "first"}).
+			AddGroup("observability",
+				exampleMiddlewareFactory{ExtraText: "telemetry"},
+				exampleMiddlewareFactory{ExtraText: "debug"},
+			).
+			Add(exampleMiddlewareFactory{ExtraText: "last"}).
+			WithHandler(exampleHandler{})
+	}
+
+	t.Run("Should apply enabled group in order", func(t *testing.T) {
+		chain, err := builder().Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		out := chain.CreateText("input")
+		expected := "input: first: telemetry: debug: last: handler"
+		if out != expected {
+			t.Errorf("Got '%s' but expected '%s'", out, expected)
+		}
+	})
+	t.Run("Should skip disabled group", func(t *testing.T) {
+		b := builder()
+		b.EnableGroup("observability", false)
+		chain, err := b.Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		out := chain.CreateText("input")
+		expected := "input: first: last: handler"
+		if out != expected {
+			t.Errorf("Got '%s' but expected '%s'", out, expected)
+		}
+	})
+	t.Run("Should name group after its name", func(t *testing.T) {
+		names := builder().Names()
+		if len(names) != 3 || names[1] != "observability" {
+			t.Errorf("Got %v but expected group name in the middle", names)
+		}
+	})
+}
+
+func TestBuilder_Plan(t *testing.T) {
+	t.Run("Should describe chain without creating it", func(t *testing.T) {
+		b := NewBuilder[textCreator]().
+			Add(Named[textCreator]("first", FactoryFunc[textCreator](func(next textCreator) (textCreator, error) {
+				t.Error("Unexpected factory call")
+				return next, nil
+			}))).
+			AddGroup("observability",
+				Named[textCreator]("telemetry", exampleMiddlewareFactory{}),
+				exampleMiddlewareFactory{},
+			).
+			AddGroup("disabled", Named[textCreator]("debug", exampleMiddlewareFactory{})).
+			WithHandler(exampleHandler{})
+		b.EnableGroup("disabled", false)
+
+		plan := b.Plan()
+		expected := []string{
+			"first",
+			"observability/telemetry",
+			"observability/middlewarebuilder.exampleMiddlewareFactory",
+			"middlewarebuilder.exampleHandler",
+		}
+		if !reflect.DeepEqual(plan, expected) {
+			t.Errorf("Got %v but expected %v", plan, expected)
+		}
+	})
+}
+
+func TestBuilder_DOT(t *testing.T) {
+	t.Run("Should describe chain as digraph ending at handler", func(t *testing.T) {
+		b := NewBuilder[textCreator]().
+			Add(Named[textCreator]("first", exampleMiddlewareFactory{})).
+			Add(exampleMiddlewareFactory{}).
+			WithHandler(exampleHandler{})
+
+		expected := `digraph chain {
+	rankdir=TB;
+	n0 [label="first"];
+	n1 [label="middlewarebuilder.exampleMiddlewareFactory"];
+	n2 [label="middlewarebuilder.exampleHandler"];
+	n0 -> n1;
+	n1 -> n2;
+}
+`
+		if dot := b.DOT(); dot != expected {
+			t.Errorf("Got %s but expected %s", dot, expected)
+		}
+	})
+	t.Run("Should not build chain", func(t *testing.T) {
+		b := NewBuilder[textCreator]().
+			Add(FactoryFunc[textCreator](func(next textCreator) (textCreator, error) {
+				t.Error("Unexpected factory call")
+				return next, nil
+			})).
+			WithHandler(exampleHandler{})
+		if dot := b.DOT(); !strings.Contains(dot, "n0 -> n1;") {
+			t.Errorf("Got %s but expected edge to handler", dot)
+		}
+		if b.Add(exampleMiddlewareFactory{}); b.err != nil {
+			t.Errorf("Expected builder to stay open for changes but got: %s", b.err)
+		}
+	})
+}
+
+func TestRequireInterface(t *testing.T) {
+	type stringer interface {
+		String() string
+	}
+	t.Run("Should pass next implementing interface", func(t *testing.T) {
+		chain, err := NewBuilder[textCreator]().
+			Add(exampleMiddlewareFactory{ExtraText: "outer"}).
+			Add(RequireInterface[textCreator, textCreator]()).
+			WithHandler(exampleHandler{}).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if text := chain.CreateText("input"); text != "input: outer: handler" {
+			t.Errorf("Got '%s' but expected 'input: outer: handler'", text)
+		}
+	})
+	t.Run("Should report incompatible next with factory index", func(t *testing.T) {
+		_, err := NewBuilder[textCreator]().
+			Add(exampleMiddlewareFactory{}).
+			Add(RequireInterface[textCreator, stringer]()).
+			WithHandler(exampleHandler{}).
+			Build()
+		if !errors.Is(err, ErrIncompatibleNext) {
+			t.Fatalf("Expected incompatible next error but got: %v", err)
+		}
+		for _, part := range []string{"factory at index 1", "middlewarebuilder.exampleHandler doesn't implement"} {
+			if !strings.Contains(err.Error(), part) {
+				t.Errorf("Expected '%s' in error '%s'", part, err)
+			}
+		}
+	})
+}
+
+func TestBuilder_WithCollectErrors(t *testing.T) {
+	errOther := errors.New("other error")
+	builder := func() *Builder[textCreator] {
+		return NewBuilder[textCreator]().
+			Add(exampleMiddlewareFactory{ExtraText: "first"}).
+			Add(Named[textCreator]("broken", FactoryFunc[textCreator](func(next textCreator) (textCreator, error) {
+				return nil, errExample
+			}))).
+			Add(exampleMiddlewareFactory{ExtraText: "third"}).
+			Add(FactoryFunc[textCreator](func(next textCreator) (textCreator, error) {
+				return nil, errOther
+			})).
+			WithHandler(exampleHandler{})
+	}
+
+	t.Run("Should report every failing factory with its index", func(t *testing.T) {
+		chain, err := builder().WithCollectErrors().Build()
+		if !errors.Is(err, errExample) || !errors.Is(err, errOther) {
+			t.Fatalf("Expected both factory errors but got: %v", err)
+		}
+		for _, part := range []string{"factory at index 1 (broken)", "factory at index 3"} {
+			if !strings.Contains(err.Error(), part) {
+				t.Errorf("Expected '%s' in error '%s'", part, err)
+			}
+		}
+		if text := chain.CreateText("input"); text != "input: first: third: handler" {
+			t.Errorf("Got '%s' but expected failed factories skipped", text)
+		}
+	})
+	t.Run("Should stop at first failure by default", func(t *testing.T) {
+		_, err := builder().Build()
+		if !errors.Is(err, errOther) || errors.Is(err, errExample) {
+			t.Errorf("Expected only the innermost factory error but got: %v", err)
+		}
+	})
+}
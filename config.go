@@ -0,0 +1,56 @@
+package middlewarebuilder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type (
+	// ChainConfig describes a middleware chain by names of registered factories.
+	ChainConfig struct {
+		Middlewares []MiddlewareConfig `json:"middlewares"`
+	}
+	// MiddlewareConfig names a registered factory and the options passed to it.
+	MiddlewareConfig struct {
+		Name    string            `json:"name"`
+		Options map[string]string `json:"options,omitempty"`
+	}
+)
+
+var errInvalidConfig = errors.New("invalid chain config")
+
+// ParseChainConfig reads a JSON chain config. Unknown keys are rejected.
+func ParseChainConfig(r io.Reader) (ChainConfig, error) {
+	var config ChainConfig
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&config); err != nil {
+		return config, fmt.Errorf("%w: %s", errInvalidConfig, err)
+	}
+	for i, m := range config.Middlewares {
+		if m.Name == "" {
+			return config, fmt.Errorf("%w: middleware %d has no name", errInvalidConfig, i)
+		}
+	}
+	return config, nil
+}
+
+// LoadChainConfig reads a JSON chain config and returns middleware names in chain order.
+func LoadChainConfig(r io.Reader) ([]string, error) {
+	config, err := ParseChainConfig(r)
+	if err != nil {
+		return nil, err
+	}
+	return config.Names(), nil
+}
+
+// Names of configured middlewares in chain order.
+func (c ChainConfig) Names() []string {
+	names := make([]string, 0, len(c.Middlewares))
+	for _, m := range c.Middlewares {
+		names = append(names, m.Name)
+	}
+	return names
+}
@@ -0,0 +1,71 @@
+package middlewarebuilder
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadChainConfig(t *testing.T) {
+	t.Run("Should return names in chain order", func(t *testing.T) {
+		names, err := LoadChainConfig(strings.NewReader(`{"middlewares": [{"name": "second"}, {"name": "first"}]}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		expected := []string{"second", "first"}
+		if !reflect.DeepEqual(names, expected) {
+			t.Errorf("Got %v but expected %v", names, expected)
+		}
+	})
+	t.Run("Should return error for unknown key", func(t *testing.T) {
+		_, err := LoadChainConfig(strings.NewReader(`{"middlewares": [{"name": "first", "order": 1}]}`))
+		if !errors.Is(err, errInvalidConfig) {
+			t.Errorf("Expected invalid config error but got: %v", err)
+		}
+	})
+	t.Run("Should return error for middleware without name", func(t *testing.T) {
+		_, err := LoadChainConfig(strings.NewReader(`{"middlewares": [{"options": {"text": "first"}}]}`))
+		if !errors.Is(err, errInvalidConfig) {
+			t.Errorf("Expected invalid config error but got: %v", err)
+		}
+	})
+}
+
+func TestRegistry_BuildConfig(t *testing.T) {
+	registry := NewRegistry[textCreator]().
+		Register("first", exampleMiddlewareFactory{ExtraText: "first"}).
+		RegisterConfigurable("text", func(options map[string]string) (Factory[textCreator], error) {
+			return exampleMiddlewareFactory{ExtraText: options["text"]}, nil
+		})
+
+	t.Run("Should create chain from config with options", func(t *testing.T) {
+		config, err := ParseChainConfig(strings.NewReader(`{"middlewares": [
+			{"name": "text", "options": {"text": "configured"}},
+			{"name": "first"}
+		]}`))
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		b := NewBuilder[textCreator]().WithHandler(exampleHandler{})
+		if err := registry.BuildConfig(b, config); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		chain, err := b.Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		out := chain.CreateText("input")
+		expected := "input: configured: first: handler"
+		if out != expected {
+			t.Errorf("Got '%s' but expected '%s'", out, expected)
+		}
+	})
+	t.Run("Should return error for options of factory without options", func(t *testing.T) {
+		config := ChainConfig{Middlewares: []MiddlewareConfig{{Name: "first", Options: map[string]string{"text": "x"}}}}
+		err := registry.BuildConfig(NewBuilder[textCreator](), config)
+		if !errors.Is(err, errUnexpectedOptions) {
+			t.Errorf("Expected unexpected options error but got: %v", err)
+		}
+	})
+}
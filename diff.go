@@ -0,0 +1,90 @@
+package middlewarebuilder
+
+import "fmt"
+
+// Diff lists differences between middleware stacks of two builders as human-readable lines:
+// middlewares added to b, removed from a and moved out of their relative order.
+// Middlewares are identified by FactoryName, so unnamed factories of the same type are told apart only by occurrence.
+func Diff[T any](a, b *Builder[T]) []string {
+	aKeys, bKeys := occurrenceKeys(a.Names()), occurrenceKeys(b.Names())
+	aIndex, bIndex := indexOf(aKeys), indexOf(bKeys)
+
+	var diff, aCommon, bCommon []string
+	for i, key := range aKeys {
+		if _, exists := bIndex[key]; !exists {
+			diff = append(diff, fmt.Sprintf("removed %s at %d", key, i))
+			continue
+		}
+		aCommon = append(aCommon, key)
+	}
+	for i, key := range bKeys {
+		if _, exists := aIndex[key]; !exists {
+			diff = append(diff, fmt.Sprintf("added %s at %d", key, i))
+			continue
+		}
+		bCommon = append(bCommon, key)
+	}
+	stable := longestCommonSubsequence(aCommon, bCommon)
+	for _, key := range aCommon {
+		if !stable[key] {
+			diff = append(diff, fmt.Sprintf("moved %s from %d to %d", key, aIndex[key], bIndex[key]))
+		}
+	}
+	return diff
+}
+
+// longestCommonSubsequence returns keys that keep their relative order in both a and b.
+func longestCommonSubsequence(a, b []string) map[string]bool {
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				lengths[i][j] = lengths[i+1][j]
+			default:
+				lengths[i][j] = lengths[i][j+1]
+			}
+		}
+	}
+	common := make(map[string]bool, lengths[0][0])
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			common[a[i]] = true
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return common
+}
+
+// occurrenceKeys suffixes repeated names with their occurrence number, e.g. "cache", "cache#2".
+func occurrenceKeys(names []string) []string {
+	seen := make(map[string]int, len(names))
+	keys := make([]string, 0, len(names))
+	for _, name := range names {
+		seen[name]++
+		if seen[name] > 1 {
+			name = fmt.Sprintf("%s#%d", name, seen[name])
+		}
+		keys = append(keys, name)
+	}
+	return keys
+}
+
+func indexOf(keys []string) map[string]int {
+	index := make(map[string]int, len(keys))
+	for i, key := range keys {
+		index[key] = i
+	}
+	return index
+}
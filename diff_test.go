@@ -0,0 +1,46 @@
+package middlewarebuilder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	builder := func(names ...string) *Builder[textCreator] {
+		b := NewBuilder[textCreator]()
+		for _, name := range names {
+			b.Add(Named[textCreator](name, exampleMiddlewareFactory{ExtraText: name}))
+		}
+		return b
+	}
+
+	t.Run("Should return no differences for identical builders", func(t *testing.T) {
+		diff := Diff(builder("telemetry", "cache"), builder("telemetry", "cache"))
+		if len(diff) != 0 {
+			t.Errorf("Expected no differences but got %v", diff)
+		}
+	})
+	t.Run("Should report moved middlewares", func(t *testing.T) {
+		diff := Diff(builder("telemetry", "debug", "cache"), builder("cache", "telemetry", "debug"))
+		expected := []string{"moved cache from 2 to 0"}
+		if !reflect.DeepEqual(diff, expected) {
+			t.Errorf("Got %v but expected %v", diff, expected)
+		}
+	})
+	t.Run("Should report added and removed middlewares", func(t *testing.T) {
+		diff := Diff(builder("telemetry", "debug", "cache"), builder("telemetry", "cache", "audit"))
+		expected := []string{"removed debug at 1", "added audit at 2"}
+		if !reflect.DeepEqual(diff, expected) {
+			t.Errorf("Got %v but expected %v", diff, expected)
+		}
+	})
+	t.Run("Should identify unnamed factories by type name", func(t *testing.T) {
+		a := NewBuilder[textCreator]().Add(exampleMiddlewareFactory{})
+		b := NewBuilder[textCreator]().Add(exampleMiddlewareFactory{}).Add(exampleMiddlewareFactory{})
+		diff := Diff(a, b)
+		expected := []string{"added middlewarebuilder.exampleMiddlewareFactory#2 at 1"}
+		if !reflect.DeepEqual(diff, expected) {
+			t.Errorf("Got %v but expected %v", diff, expected)
+		}
+	})
+}
@@ -0,0 +1,58 @@
+package httphandler
+
+import (
+	"github.com/jlisicki/middlewarebuilder"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// statusRecorder captures status and number of bytes written to a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the original writer.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// AccessLog logs method, path, status, bytes written and latency of every request.
+// It only observes the response, so it may be placed anywhere in a chain.
+func AccessLog(logger *slog.Logger) middlewarebuilder.Factory[http.Handler] {
+	return middlewarebuilder.Named[http.Handler]("AccessLog", middlewarebuilder.FactoryFunc[http.Handler](func(next http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			sT := time.Now()
+			recorder := &statusRecorder{ResponseWriter: writer}
+			next.ServeHTTP(recorder, request)
+			if recorder.status == 0 {
+				recorder.status = http.StatusOK
+			}
+			logger.LogAttrs(request.Context(), slog.LevelInfo, "access",
+				slog.String("method", request.Method),
+				slog.String("path", request.URL.Path),
+				slog.Int("status", recorder.status),
+				slog.Int("bytes", recorder.bytes),
+				slog.Duration("latency", time.Since(sT)),
+			)
+		}), nil
+	}))
+}
@@ -0,0 +1,53 @@
+package httphandler
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/jlisicki/middlewarebuilder"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessLog(t *testing.T) {
+	t.Run("Should log request and response attributes", func(t *testing.T) {
+		var out bytes.Buffer
+		handler, err := middlewarebuilder.NewBuilder[http.Handler]().
+			Add(AccessLog(slog.New(slog.NewJSONHandler(&out, nil)))).
+			WithHandler(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				writer.WriteHeader(http.StatusCreated)
+				_, _ = writer.Write([]byte("created"))
+			})).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/users?x=1", nil))
+
+		var entry map[string]any
+		if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+			t.Fatalf("Unable to parse log entry %q: %s", out.String(), err)
+		}
+		expected := map[string]any{"method": "POST", "path": "/users", "status": 201.0, "bytes": 7.0}
+		for key, value := range expected {
+			if entry[key] != value {
+				t.Errorf("Got %s %v but expected %v", key, entry[key], value)
+			}
+		}
+		if _, ok := entry["latency"]; !ok {
+			t.Error("Expected latency to be logged")
+		}
+	})
+	t.Run("Should default status to 200", func(t *testing.T) {
+		var out bytes.Buffer
+		handler, _ := AccessLog(slog.New(slog.NewJSONHandler(&out, nil))).Create(GetUserHandler{})
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/10", nil))
+
+		var entry map[string]any
+		_ = json.Unmarshal(out.Bytes(), &entry)
+		if entry["status"] != 200.0 || entry["bytes"] != 4.0 {
+			t.Errorf("Got status %v and bytes %v but expected 200 and 4", entry["status"], entry["bytes"])
+		}
+	})
+}
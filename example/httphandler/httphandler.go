@@ -2,7 +2,7 @@ package httphandler
 
 import (
 	"github.com/jlisicki/middlewarebuilder"
-	"log"
+	"log/slog"
 	"net/http"
 )
 
@@ -18,12 +18,7 @@ var _ http.Handler = GetUserHandler{}
 // CreateGetUserHandler creates user handler with all required middlewares.
 func CreateGetUserHandler() (http.Handler, error) {
 	return middlewarebuilder.NewBuilder[http.Handler]().
-		Add(middlewarebuilder.FactoryFunc[http.Handler](func(next http.Handler) (http.Handler, error) {
-			return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-				log.Printf("%s %s", request.Method, request.URL)
-				next.ServeHTTP(writer, request)
-			}), nil
-		})).
+		Add(AccessLog(slog.Default())).
 		WithHandler(GetUserHandler{}).
 		Build()
 }
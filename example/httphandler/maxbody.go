@@ -0,0 +1,21 @@
+package httphandler
+
+import (
+	"github.com/jlisicki/middlewarebuilder"
+	"net/http"
+)
+
+// MaxBody limits request bodies to n bytes. Requests declaring a larger body are rejected
+// with 413 before reaching next, while reads of bodies of unknown length fail with *http.MaxBytesError past the limit.
+func MaxBody(n int64) middlewarebuilder.Factory[http.Handler] {
+	return middlewarebuilder.Named[http.Handler]("MaxBody", middlewarebuilder.FactoryFunc[http.Handler](func(next http.Handler) (http.Handler, error) {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			if request.ContentLength > n {
+				http.Error(writer, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			request.Body = http.MaxBytesReader(writer, request.Body, n)
+			next.ServeHTTP(writer, request)
+		}), nil
+	}))
+}
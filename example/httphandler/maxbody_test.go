@@ -0,0 +1,54 @@
+package httphandler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestMaxBody(t *testing.T) {
+	var read string
+	var readErr error
+	handler, _ := MaxBody(10).Create(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, err := io.ReadAll(request.Body)
+		read, readErr = string(body), err
+		if err != nil {
+			http.Error(writer, err.Error(), http.StatusRequestEntityTooLarge)
+		}
+	}))
+
+	t.Run("Should pass body under the limit", func(t *testing.T) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("small")))
+		if recorder.Code != http.StatusOK || read != "small" || readErr != nil {
+			t.Errorf("Got status %d, body %q and error %v but expected 200 with full body", recorder.Code, read, readErr)
+		}
+	})
+	t.Run("Should reject body over the limit before calling next", func(t *testing.T) {
+		read, readErr = "", nil
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("way too large body")))
+		if recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Got status %d but expected 413", recorder.Code)
+		}
+		if read != "" {
+			t.Errorf("Expected next not to be called but it read %q", read)
+		}
+	})
+	t.Run("Should fail reads past the limit of body of unknown length", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodPost, "/users", io.NopCloser(strings.NewReader("way too large body")))
+		request.ContentLength = -1
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		var maxBytesErr *http.MaxBytesError
+		if !errors.As(readErr, &maxBytesErr) {
+			t.Errorf("Expected max bytes error but got: %v", readErr)
+		}
+		if recorder.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Got status %d but expected 413", recorder.Code)
+		}
+	})
+}
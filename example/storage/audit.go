@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// AuditRecord describes a successful write made by a principal.
+	AuditRecord[K Identifier] struct {
+		ID        K
+		Op        string
+		Principal string
+		Time      time.Time
+	}
+	AuditSink[K Identifier] interface {
+		Record(ctx context.Context, record AuditRecord[K])
+	}
+
+	// Audit records successful writes along with principal read from context.
+	Audit[T Entity[K], K Identifier] struct {
+		Next Repository[T, K]
+		Sink AuditSink[K]
+		// Now returns current time. Defaults to time.Now.
+		Now func() time.Time
+	}
+)
+
+type principalCtxKey string
+
+var principalKey principalCtxKey = "principal"
+
+// UnknownPrincipal is recorded for writes without principal in context.
+const UnknownPrincipal = "unknown"
+
+// ContextWithPrincipal sets principal acting in context.
+func ContextWithPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, principalKey, principal)
+}
+
+func principalFromContext(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(principalKey).(string)
+	return principal, ok
+}
+
+func (a Audit[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return a.Next.Get(ctx, id)
+}
+
+func (a Audit[T, K]) Set(ctx context.Context, entity T) error {
+	if err := a.Next.Set(ctx, entity); err != nil {
+		return err
+	}
+	a.record(ctx, "Set", entity.Identifier())
+	return nil
+}
+
+func (a Audit[T, K]) Delete(ctx context.Context, id K) error {
+	if err := a.Next.Delete(ctx, id); err != nil {
+		return err
+	}
+	a.record(ctx, "Delete", id)
+	return nil
+}
+
+func (a Audit[T, K]) record(ctx context.Context, op string, id K) {
+	principal, ok := principalFromContext(ctx)
+	if !ok {
+		principal = UnknownPrincipal
+	}
+	now := time.Now
+	if a.Now != nil {
+		now = a.Now
+	}
+	a.Sink.Record(ctx, AuditRecord[K]{ID: id, Op: op, Principal: principal, Time: now()})
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type auditRecords []AuditRecord[UserID]
+
+func (a *auditRecords) Record(ctx context.Context, record AuditRecord[UserID]) {
+	*a = append(*a, record)
+}
+
+func TestAudit(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	newAudit := func(next Repository[User, UserID]) (Audit[User, UserID], *auditRecords) {
+		records := &auditRecords{}
+		return Audit[User, UserID]{
+			Next: next,
+			Sink: records,
+			Now: func() time.Time {
+				return now
+			},
+		}, records
+	}
+
+	t.Run("Should record writes with principal", func(t *testing.T) {
+		repo, records := newAudit(&stubRepository{})
+		ctx := ContextWithPrincipal(context.Background(), "admin")
+		_ = repo.Set(ctx, User{ID: "10"})
+		_ = repo.Delete(context.Background(), "10")
+		expected := auditRecords{
+			{ID: "10", Op: "Set", Principal: "admin", Time: now},
+			{ID: "10", Op: "Delete", Principal: UnknownPrincipal, Time: now},
+		}
+		if len(*records) != len(expected) {
+			t.Fatalf("Got %d records but expected %d", len(*records), len(expected))
+		}
+		for i, record := range *records {
+			if record != expected[i] {
+				t.Errorf("Got %v but expected %v", record, expected[i])
+			}
+		}
+	})
+	t.Run("Should not record reads and failed writes", func(t *testing.T) {
+		repo, records := newAudit(&stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			return errExample
+		}})
+		_, _ = repo.Get(context.Background(), "10")
+		_ = repo.Set(context.Background(), User{ID: "10"})
+		if len(*records) != 0 {
+			t.Errorf("Expected no records but got %v", *records)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"time"
+)
+
+// BufferedWriter coalesces writes and flushes them to the underlying writer periodically and on Close.
+// Every Write is appended whole, so lines written by a goroutine keep their order.
+type BufferedWriter struct {
+	out       io.Writer
+	lock      sync.Mutex
+	buf       bytes.Buffer
+	stop      chan struct{}
+	stopped   chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// DefaultFlushInterval is used by NewBufferedWriter for intervals not greater than zero.
+const DefaultFlushInterval = time.Second
+
+func NewBufferedWriter(out io.Writer, interval time.Duration) *BufferedWriter {
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	w := &BufferedWriter{
+		out:     out,
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go w.flushPeriodically(interval)
+	return w
+}
+
+// Write buffers p. It returns an error of a failed flush, if any.
+func (w *BufferedWriter) Write(p []byte) (int, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+// Flush writes buffered data to the underlying writer.
+func (w *BufferedWriter) Flush() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.err != nil || w.buf.Len() == 0 {
+		return w.err
+	}
+	_, w.err = w.out.Write(w.buf.Bytes())
+	w.buf.Reset()
+	return w.err
+}
+
+// Close stops periodic flushing and flushes remaining data.
+func (w *BufferedWriter) Close() error {
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.stopped
+	})
+	return w.Flush()
+}
+
+func (w *BufferedWriter) flushPeriodically(interval time.Duration) {
+	defer close(w.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			_ = w.Flush()
+		case <-w.stop:
+			return
+		}
+	}
+}
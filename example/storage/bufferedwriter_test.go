@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type countingWriter struct {
+	lock   sync.Mutex
+	buf    bytes.Buffer
+	writes int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.writes++
+	return c.buf.Write(p)
+}
+
+func (c *countingWriter) String() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.buf.String()
+}
+
+func TestBufferedWriter(t *testing.T) {
+	ctx := ContextWithEnabledDebug(context.Background())
+	t.Run("Should coalesce debug lines into fewer writes", func(t *testing.T) {
+		out := &countingWriter{}
+		w := NewBufferedWriter(out, time.Hour)
+		repo := Debug[User, UserID]{Next: &stubRepository{}, Output: w, Label: "Buffered"}
+		for i := 0; i < 100; i++ {
+			_, _ = repo.Get(ctx, "10")
+		}
+		if out.String() != "" {
+			t.Error("Expected no output before flush")
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if lines := strings.Count(out.String(), "[DEBUG][Buffered] PreGet\n"); lines != 100 {
+			t.Errorf("Got %d lines but expected 100", lines)
+		}
+		if out.writes != 1 {
+			t.Errorf("Got %d writes but expected 1", out.writes)
+		}
+	})
+	t.Run("Should flush periodically", func(t *testing.T) {
+		out := &countingWriter{}
+		w := NewBufferedWriter(out, time.Millisecond)
+		defer func() {
+			_ = w.Close()
+		}()
+		repo := Debug[User, UserID]{Next: &stubRepository{}, Output: w, Label: "Buffered"}
+		_ = repo.Delete(ctx, "10")
+		deadline := time.Now().Add(time.Second)
+		for out.String() == "" && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if out.String() != "[DEBUG][Buffered] PreDelete\n" {
+			t.Errorf("Got '%s' but expected flushed line", out.String())
+		}
+	})
+	t.Run("Should flush on Close with default interval", func(t *testing.T) {
+		out := &countingWriter{}
+		w := NewBufferedWriter(out, 0)
+		_, _ = w.Write([]byte("line\n"))
+		if err := w.Close(); err != nil || out.String() != "line\n" {
+			t.Errorf("Got '%s', %v but expected flushed line", out.String(), err)
+		}
+	})
+}
@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Bulkhead caps number of concurrent downstream operations.
+type Bulkhead[T Entity[K], K Identifier] struct {
+	Next  Repository[T, K]
+	slots chan struct{}
+	// QueueTimeout bounds time spent waiting for a free slot. Zero waits until context is done.
+	QueueTimeout time.Duration
+}
+
+// ErrBulkheadFull is returned when no slot became free within QueueTimeout.
+var ErrBulkheadFull = errors.New("too many concurrent operations")
+
+func NewBulkhead[T Entity[K], K Identifier](next Repository[T, K], maxConcurrent int, queueTimeout time.Duration) *Bulkhead[T, K] {
+	return &Bulkhead[T, K]{
+		Next:         next,
+		slots:        make(chan struct{}, maxConcurrent),
+		QueueTimeout: queueTimeout,
+	}
+}
+
+func (b *Bulkhead[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if err := b.acquire(ctx); err != nil {
+		var entity T
+		return entity, err
+	}
+	defer b.release()
+	return b.Next.Get(ctx, id)
+}
+
+func (b *Bulkhead[T, K]) Set(ctx context.Context, entity T) error {
+	if err := b.acquire(ctx); err != nil {
+		return err
+	}
+	defer b.release()
+	return b.Next.Set(ctx, entity)
+}
+
+func (b *Bulkhead[T, K]) Delete(ctx context.Context, id K) error {
+	if err := b.acquire(ctx); err != nil {
+		return err
+	}
+	defer b.release()
+	return b.Next.Delete(ctx, id)
+}
+
+func (b *Bulkhead[T, K]) acquire(ctx context.Context) error {
+	var timeout <-chan time.Time
+	if b.QueueTimeout > 0 {
+		timer := time.NewTimer(b.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case b.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timeout:
+		return ErrBulkheadFull
+	}
+}
+
+func (b *Bulkhead[T, K]) release() {
+	<-b.slots
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBulkhead(t *testing.T) {
+	t.Run("Should limit concurrent downstream operations", func(t *testing.T) {
+		const maxConcurrent = 3
+		var running, peak int32
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				observed := atomic.LoadInt32(&peak)
+				if current <= observed || atomic.CompareAndSwapInt32(&peak, observed, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return User{ID: id}, nil
+		}}
+		repo := NewBulkhead[User, UserID](next, maxConcurrent, 0)
+		var wg sync.WaitGroup
+		for i := 0; i < 30; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := repo.Get(context.Background(), "10"); err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+			}()
+		}
+		wg.Wait()
+		if peak > maxConcurrent {
+			t.Errorf("Got %d concurrent operations but expected at most %d", peak, maxConcurrent)
+		}
+	})
+	t.Run("Should return error after queue timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			<-release
+			return nil
+		}}
+		repo := NewBulkhead[User, UserID](next, 1, time.Millisecond)
+		go func() {
+			_ = repo.Set(context.Background(), User{ID: "10"})
+		}()
+		for next.count("Set") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if err := repo.Delete(context.Background(), "10"); !errors.Is(err, ErrBulkheadFull) {
+			t.Errorf("Expected bulkhead full error but got: %v", err)
+		}
+		close(release)
+	})
+	t.Run("Should stop waiting on cancelled context", func(t *testing.T) {
+		repo := NewBulkhead[User, UserID](&stubRepository{}, 0, 0)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := repo.Delete(ctx, "10"); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context cancelled error but got: %v", err)
+		}
+	})
+}
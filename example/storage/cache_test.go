@@ -0,0 +1,205 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"unsafe"
+)
+
+func TestCache_Cacheable(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should cache only matching entities", func(t *testing.T) {
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id, Name: "user " + string(id)}, nil
+		}}
+		cache := &Cache[User, UserID]{
+			Next: next,
+			Cacheable: func(u User) bool {
+				return u.ID != "volatile"
+			},
+			cached: make(map[UserID]User),
+		}
+		for i := 0; i < 2; i++ {
+			_, _ = cache.Get(ctx, "stable")
+			_, _ = cache.Get(ctx, "volatile")
+		}
+		if calls := next.count("Get"); calls != 3 {
+			t.Errorf("Got %d downstream Gets but expected 3", calls)
+		}
+	})
+	t.Run("Should invalidate on Set regardless of predicate", func(t *testing.T) {
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id}, nil
+		}}
+		cacheable := true
+		cache := &Cache[User, UserID]{
+			Next: next,
+			Cacheable: func(u User) bool {
+				return cacheable
+			},
+			cached: make(map[UserID]User),
+		}
+		_, _ = cache.Get(ctx, "10")
+		cacheable = false
+		_ = cache.Set(ctx, User{ID: "10"})
+		_, _ = cache.Get(ctx, "10")
+		if calls := next.count("Get"); calls != 2 {
+			t.Errorf("Got %d downstream Gets but expected 2", calls)
+		}
+	})
+}
+
+func TestCache_Warm(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should short-circuit reads of keys not warmed yet", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			if id == "slow" {
+				close(started)
+				<-release
+			}
+			return User{ID: id}, nil
+		}}
+		cache := &Cache[User, UserID]{
+			Next: next,
+			WarmingBypass: func(id UserID) bool {
+				return id == "bypass"
+			},
+			cached: make(map[UserID]User),
+		}
+		warmed := make(chan error)
+		go func() {
+			warmed <- cache.Warm(ctx, []UserID{"10", "slow"})
+		}()
+		<-started
+
+		if user, err := cache.Get(ctx, "10"); err != nil || user.ID != "10" {
+			t.Errorf("Got %v, %v but expected warmed entity", user, err)
+		}
+		if _, err := cache.Get(ctx, "11"); !errors.Is(err, ErrCacheWarming) {
+			t.Errorf("Expected cache warming error but got: %v", err)
+		}
+		if _, err := cache.Get(ctx, "bypass"); err != nil {
+			t.Errorf("Unexpected error of bypassing key: %s", err)
+		}
+		close(release)
+		if err := <-warmed; err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		if _, err := cache.Get(ctx, "11"); err != nil {
+			t.Errorf("Unexpected error after warm-up: %s", err)
+		}
+		if calls := next.count("Get"); calls != 4 {
+			t.Errorf("Got %d downstream Gets but expected 4", calls)
+		}
+	})
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	ctx := context.Background()
+	newCache := func() (*Cache[User, UserID], *stubRepository) {
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id}, nil
+		}}
+		return &Cache[User, UserID]{Next: next, cached: make(map[UserID]User)}, next
+	}
+
+	t.Run("Should re-fetch invalidated entity only", func(t *testing.T) {
+		cache, next := newCache()
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "11")
+		cache.Invalidate("10")
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "11")
+		if calls := next.count("Get"); calls != 3 {
+			t.Errorf("Got %d downstream Gets but expected 3", calls)
+		}
+	})
+	t.Run("Should re-fetch all entities after InvalidateAll", func(t *testing.T) {
+		cache, next := newCache()
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "11")
+		cache.InvalidateAll()
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "11")
+		if calls := next.count("Get"); calls != 4 {
+			t.Errorf("Got %d downstream Gets but expected 4", calls)
+		}
+	})
+}
+
+func TestCache_Intern(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should share single copy of equal entities and evict it with last reference", func(t *testing.T) {
+		// Aliases resolve to the same entity, each time freshly allocated.
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: "10", Name: strings.Repeat("x", 1024)}, nil
+		}}
+		cache := &Cache[User, UserID]{Next: next, Intern: userSerializer{}, cached: make(map[UserID]User)}
+		first, _ := cache.Get(ctx, "alias-1")
+		second, _ := cache.Get(ctx, "alias-2")
+		if unsafe.StringData(first.Name) != unsafe.StringData(second.Name) {
+			t.Error("Expected equal entities to share storage")
+		}
+		if len(cache.interned) != 1 {
+			t.Fatalf("Got %d interned entities but expected 1", len(cache.interned))
+		}
+
+		cache.Invalidate("alias-1")
+		if len(cache.interned) != 1 {
+			t.Errorf("Got %d interned entities but expected 1 still referenced", len(cache.interned))
+		}
+		if user, _ := cache.Get(ctx, "alias-2"); unsafe.StringData(user.Name) != unsafe.StringData(second.Name) {
+			t.Error("Expected remaining alias to keep shared entity")
+		}
+		cache.Invalidate("alias-2")
+		if len(cache.interned) != 0 {
+			t.Errorf("Got %d interned entities but expected none", len(cache.interned))
+		}
+		if calls := next.count("Get"); calls != 2 {
+			t.Errorf("Got %d downstream Gets but expected 2", calls)
+		}
+	})
+	t.Run("Should keep distinct entities apart", func(t *testing.T) {
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id}, nil
+		}}
+		cache := &Cache[User, UserID]{Next: next, Intern: userSerializer{}, cached: make(map[UserID]User)}
+		_, _ = cache.Get(ctx, "10")
+		if user, _ := cache.Get(ctx, "11"); user.ID != "11" {
+			t.Errorf("Got %v but expected entity 11", user)
+		}
+		if len(cache.interned) != 2 {
+			t.Errorf("Got %d interned entities but expected 2", len(cache.interned))
+		}
+		cache.InvalidateAll()
+		if len(cache.interned) != 0 {
+			t.Errorf("Got %d interned entities but expected none", len(cache.interned))
+		}
+	})
+}
+
+func TestCache_MaxEntries(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should evict entity to stay within bound", func(t *testing.T) {
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id}, nil
+		}}
+		cache := &Cache[User, UserID]{Next: next, MaxEntries: 2, cached: make(map[UserID]User)}
+		for _, id := range []UserID{"10", "11", "12", "12"} {
+			_, _ = cache.Get(ctx, id)
+		}
+		if len(cache.cached) != 2 {
+			t.Errorf("Got %d cached entities but expected 2", len(cache.cached))
+		}
+		if _, cached := cache.cached["12"]; !cached {
+			t.Error("Expected latest entity to be cached")
+		}
+		if calls := next.count("Get"); calls != 3 {
+			t.Errorf("Got %d downstream Gets but expected 3", calls)
+		}
+	})
+}
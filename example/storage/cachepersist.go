@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+)
+
+// Persist writes cached entities to w, each framed like in Export, so a restarted cache can be warmed with LoadPersisted.
+// Entities are written from a snapshot taken when Persist starts.
+func (c *Cache[T, K]) Persist(w io.Writer) error {
+	if c.Serializer == nil {
+		return fmt.Errorf("%w: Persist without serializer", errUnsupported)
+	}
+	c.lock.Lock()
+	entities := make([]T, 0, len(c.cached))
+	for _, entity := range c.cached {
+		entities = append(entities, entity)
+	}
+	c.lock.Unlock()
+	for _, entity := range entities {
+		raw, err := c.Serializer.Serialize(entity)
+		if err != nil {
+			return fmt.Errorf("%w entity: %w", ErrSerialize, err)
+		}
+		if err := writeFrame(w, raw); err != nil {
+			return fmt.Errorf("unable to write entity: %w", err)
+		}
+	}
+	return nil
+}
+
+// LoadPersisted caches entities written by Persist under their identifiers.
+// A corrupt snapshot is ignored with a warning, leaving the cache as it was, so it never fails startup.
+func (c *Cache[T, K]) LoadPersisted(r io.Reader) error {
+	if c.Serializer == nil {
+		return fmt.Errorf("%w: LoadPersisted without serializer", errUnsupported)
+	}
+	var entities []T
+	for {
+		raw, err := readFrame(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			c.logger().Printf("warning: ignoring corrupt cache snapshot: unable to read entity: %s", err)
+			return nil
+		}
+		entity, err := c.Serializer.UnSerialize(raw)
+		if err != nil {
+			c.logger().Printf("warning: ignoring corrupt cache snapshot: %s entity: %s", ErrDeserialize, err)
+			return nil
+		}
+		entities = append(entities, entity)
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, entity := range entities {
+		c.store(entity.Identifier(), entity)
+	}
+	return nil
+}
+
+func (c *Cache[T, K]) logger() *log.Logger {
+	if c.Logger == nil {
+		return log.Default()
+	}
+	return c.Logger
+}
+
+// Close persists cached entities to PersistOnClose when set.
+func (c *Cache[T, K]) Close() error {
+	if c.PersistOnClose == nil {
+		return nil
+	}
+	return c.Persist(c.PersistOnClose)
+}
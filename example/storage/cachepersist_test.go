@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestCache_Persist(t *testing.T) {
+	ctx := context.Background()
+	backend := func() *stubRepository {
+		return &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id, Name: "user " + string(id)}, nil
+		}}
+	}
+
+	t.Run("Should restore persisted entities", func(t *testing.T) {
+		var snapshot bytes.Buffer
+		cache := &Cache[User, UserID]{Next: backend(), Serializer: userSerializer{}, PersistOnClose: &snapshot, cached: make(map[UserID]User)}
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "11")
+		if err := cache.Close(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		next := backend()
+		restarted := &Cache[User, UserID]{Next: next, Serializer: userSerializer{}, cached: make(map[UserID]User)}
+		if err := restarted.LoadPersisted(&snapshot); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		for _, id := range []UserID{"10", "11"} {
+			if user, _ := restarted.Get(ctx, id); user.Name != "user "+string(id) {
+				t.Errorf("Got %v but expected restored user %s", user, id)
+			}
+		}
+		if calls := next.count("Get"); calls != 0 {
+			t.Errorf("Got %d downstream Gets but expected none", calls)
+		}
+	})
+	t.Run("Should ignore corrupt snapshot with a warning", func(t *testing.T) {
+		var buf bytes.Buffer
+		var snapshot bytes.Buffer
+		_ = writeFrame(&snapshot, []byte(`{"ID":"10"}`))
+		_ = writeFrame(&snapshot, []byte("not a user"))
+		cache := &Cache[User, UserID]{Next: backend(), Serializer: userSerializer{}, Logger: log.New(&buf, "", 0), cached: make(map[UserID]User)}
+		if err := cache.LoadPersisted(&snapshot); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(cache.cached) != 0 {
+			t.Errorf("Got %d cached entities but expected none", len(cache.cached))
+		}
+		if !strings.Contains(buf.String(), "warning: ignoring corrupt cache snapshot") {
+			t.Errorf("Expected warning but got '%s'", buf.String())
+		}
+	})
+	t.Run("Should ignore truncated snapshot", func(t *testing.T) {
+		var snapshot bytes.Buffer
+		_ = writeFrame(&snapshot, []byte(`{"ID":"10"}`))
+		cache := &Cache[User, UserID]{Next: backend(), Serializer: userSerializer{}, Logger: discardLogger, cached: make(map[UserID]User)}
+		if err := cache.LoadPersisted(bytes.NewReader(snapshot.Bytes()[:snapshot.Len()-1])); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(cache.cached) != 0 {
+			t.Errorf("Got %d cached entities but expected none", len(cache.cached))
+		}
+	})
+}
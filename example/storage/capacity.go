@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Capacity bounds number of entities in a repository implementing Counter. Sets of new entities
+// at capacity are rejected, unless Evict picks an entity to delete to make room. Updates are always allowed.
+// Writes are serialized, so concurrent Sets can't exceed capacity.
+type Capacity[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+	Max  int
+	// Evict returns identifier of entity to delete at capacity, e.g. the oldest one.
+	Evict func(ctx context.Context) (K, error)
+	lock  sync.Mutex
+}
+
+// ErrAtCapacity is returned for Sets of new entities rejected by Capacity.
+var ErrAtCapacity = errors.New("repository at capacity")
+
+func (c *Capacity[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return c.Next.Get(ctx, id)
+}
+
+func (c *Capacity[T, K]) Set(ctx context.Context, entity T) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	counter, ok := c.Next.(Counter)
+	if !ok {
+		return fmt.Errorf("%w: Count", errUnsupported)
+	}
+	count, err := counter.Count(ctx)
+	if err != nil {
+		return err
+	}
+	if count < c.Max {
+		return c.Next.Set(ctx, entity)
+	}
+	if _, err := c.Next.Get(ctx, entity.Identifier()); err == nil {
+		return c.Next.Set(ctx, entity)
+	} else if !errors.Is(err, errNotFound) {
+		return err
+	}
+	if c.Evict == nil {
+		return ErrAtCapacity
+	}
+	evicted, err := c.Evict(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to evict entity: %w", err)
+	}
+	if err := c.Next.Delete(ctx, evicted); err != nil {
+		return fmt.Errorf("unable to evict entity: %w", err)
+	}
+	return c.Next.Set(ctx, entity)
+}
+
+func (c *Capacity[T, K]) Delete(ctx context.Context, id K) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.Next.Delete(ctx, id)
+}
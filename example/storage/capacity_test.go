@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCapacity_Set(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should reject new entities at capacity", func(t *testing.T) {
+		repo := &Capacity[User, UserID]{Next: NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}), Max: 2}
+		_ = repo.Set(ctx, User{ID: "10"})
+		_ = repo.Set(ctx, User{ID: "11"})
+		if err := repo.Set(ctx, User{ID: "12"}); !errors.Is(err, ErrAtCapacity) {
+			t.Errorf("Expected at capacity error but got: %v", err)
+		}
+		if err := repo.Set(ctx, User{ID: "11", Name: "updated"}); err != nil {
+			t.Errorf("Unexpected error of update at capacity: %s", err)
+		}
+		_ = repo.Delete(ctx, "10")
+		if err := repo.Set(ctx, User{ID: "12"}); err != nil {
+			t.Errorf("Unexpected error after making room: %s", err)
+		}
+	})
+	t.Run("Should evict oldest entity at capacity", func(t *testing.T) {
+		var inserted []UserID
+		repo := &Capacity[User, UserID]{
+			Next: NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}),
+			Max:  2,
+			Evict: func(ctx context.Context) (UserID, error) {
+				oldest := inserted[0]
+				inserted = inserted[1:]
+				return oldest, nil
+			},
+		}
+		for _, id := range []UserID{"10", "11", "12"} {
+			if err := repo.Set(ctx, User{ID: id}); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			inserted = append(inserted, id)
+		}
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected oldest entity to be evicted but got: %v", err)
+		}
+		for _, id := range []UserID{"11", "12"} {
+			if _, err := repo.Get(ctx, id); err != nil {
+				t.Errorf("Unexpected error of entity %s: %s", id, err)
+			}
+		}
+	})
+	t.Run("Should require countable repository", func(t *testing.T) {
+		repo := &Capacity[User, UserID]{Next: &stubRepository{}, Max: 1}
+		if err := repo.Set(ctx, User{ID: "10"}); !errors.Is(err, errUnsupported) {
+			t.Errorf("Expected unsupported error but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package storage
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// ChangeEvent describes a successful write.
+	ChangeEvent[T Entity[K], K Identifier] struct {
+		Op     string
+		ID     K
+		Entity T
+	}
+
+	// OverflowPolicy decides what CDC does when events buffer is full.
+	OverflowPolicy int
+
+	// CDC emits change events to a buffered channel after successful Set and Delete.
+	CDC[T Entity[K], K Identifier] struct {
+		Next      Repository[T, K]
+		policy    OverflowPolicy
+		events    chan ChangeEvent[T, K]
+		done      chan struct{}
+		closeOnce sync.Once
+		lock      sync.RWMutex
+		closed    bool
+		dropped   int64
+	}
+)
+
+const (
+	// DropWhenFull discards events that don't fit into the buffer.
+	DropWhenFull OverflowPolicy = iota
+	// BlockWhenFull waits for buffer space, until context is done or CDC is closed.
+	BlockWhenFull
+)
+
+func NewCDC[T Entity[K], K Identifier](next Repository[T, K], buffer int, policy OverflowPolicy) *CDC[T, K] {
+	return &CDC[T, K]{
+		Next:   next,
+		policy: policy,
+		events: make(chan ChangeEvent[T, K], buffer),
+		done:   make(chan struct{}),
+	}
+}
+
+// Events returns channel of change events. It's closed by Close.
+func (c *CDC[T, K]) Events() <-chan ChangeEvent[T, K] {
+	return c.events
+}
+
+// Dropped returns number of events that weren't emitted.
+func (c *CDC[T, K]) Dropped() int64 {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.dropped
+}
+
+// Close stops emitting events and closes events channel, so consumers can drain buffered events.
+func (c *CDC[T, K]) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		c.closed = true
+		close(c.events)
+	})
+	return nil
+}
+
+func (c *CDC[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return c.Next.Get(ctx, id)
+}
+
+func (c *CDC[T, K]) Set(ctx context.Context, entity T) error {
+	if err := c.Next.Set(ctx, entity); err != nil {
+		return err
+	}
+	c.emit(ctx, ChangeEvent[T, K]{Op: "Set", ID: entity.Identifier(), Entity: entity})
+	return nil
+}
+
+func (c *CDC[T, K]) Delete(ctx context.Context, id K) error {
+	if err := c.Next.Delete(ctx, id); err != nil {
+		return err
+	}
+	c.emit(ctx, ChangeEvent[T, K]{Op: "Delete", ID: id})
+	return nil
+}
+
+func (c *CDC[T, K]) emit(ctx context.Context, event ChangeEvent[T, K]) {
+	c.lock.RLock()
+	if c.closed {
+		c.lock.RUnlock()
+		c.drop()
+		return
+	}
+	sent := false
+	if c.policy == BlockWhenFull {
+		select {
+		case c.events <- event:
+			sent = true
+		case <-ctx.Done():
+		case <-c.done:
+		}
+	} else {
+		select {
+		case c.events <- event:
+			sent = true
+		default:
+		}
+	}
+	c.lock.RUnlock()
+	if !sent {
+		c.drop()
+	}
+}
+
+func (c *CDC[T, K]) drop() {
+	c.lock.Lock()
+	c.dropped++
+	c.lock.Unlock()
+}
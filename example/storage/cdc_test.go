@@ -0,0 +1,72 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestCDC(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should emit events in order of successful writes", func(t *testing.T) {
+		cdc := NewCDC[User, UserID](NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}), 10, BlockWhenFull)
+		_ = cdc.Set(ctx, User{ID: "10", Name: "John"})
+		_ = cdc.Set(ctx, User{ID: "11", Name: "Jane"})
+		_ = cdc.Delete(ctx, "10")
+		_ = cdc.Close()
+
+		var events []ChangeEvent[User, UserID]
+		for event := range cdc.Events() {
+			events = append(events, event)
+		}
+		expected := []ChangeEvent[User, UserID]{
+			{Op: "Set", ID: "10", Entity: User{ID: "10", Name: "John"}},
+			{Op: "Set", ID: "11", Entity: User{ID: "11", Name: "Jane"}},
+			{Op: "Delete", ID: "10"},
+		}
+		if len(events) != len(expected) {
+			t.Fatalf("Got %d events but expected %d", len(events), len(expected))
+		}
+		for i, event := range events {
+			if event != expected[i] {
+				t.Errorf("Got event %v but expected %v", event, expected[i])
+			}
+		}
+	})
+	t.Run("Should not emit events for failed writes", func(t *testing.T) {
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			return errExample
+		}}
+		cdc := NewCDC[User, UserID](next, 10, DropWhenFull)
+		if err := cdc.Set(ctx, User{ID: "10"}); !errors.Is(err, errExample) {
+			t.Errorf("Expected example error but got: %v", err)
+		}
+		_ = cdc.Close()
+		if _, open := <-cdc.Events(); open {
+			t.Error("Expected no events")
+		}
+	})
+	t.Run("Should drop events when buffer is full", func(t *testing.T) {
+		cdc := NewCDC[User, UserID](&stubRepository{}, 1, DropWhenFull)
+		_ = cdc.Set(ctx, User{ID: "10"})
+		_ = cdc.Set(ctx, User{ID: "11"})
+		_ = cdc.Delete(ctx, "10")
+		if dropped := cdc.Dropped(); dropped != 2 {
+			t.Errorf("Got %d dropped events but expected 2", dropped)
+		}
+		_ = cdc.Close()
+		if event := <-cdc.Events(); event.ID != "10" {
+			t.Errorf("Got event for '%s' but expected '10'", event.ID)
+		}
+	})
+	t.Run("Should stop blocking on close", func(t *testing.T) {
+		cdc := NewCDC[User, UserID](&stubRepository{}, 0, BlockWhenFull)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			_ = cdc.Set(ctx, User{ID: "10"})
+		}()
+		_ = cdc.Close()
+		<-done
+	})
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/jlisicki/middlewarebuilder"
+	"sync"
+)
+
+type (
+	// CoalesceWrites performs a single downstream Set for concurrent Sets of an identical entity.
+	// A Set joins a write in flight only if it's the latest write of the identifier, so no write
+	// started in between is overridden. The shared write isn't cancelled with context of its first caller,
+	// but keeps its deadline. A panic of the shared write is returned to joined callers as middlewarebuilder.ErrFlightPanicked.
+	CoalesceWrites[T Entity[K], K Identifier] struct {
+		Next                 Repository[T, K]
+		identifierSerializer serializer[K]
+		entitySerializer     serializer[T]
+		lock                 sync.Mutex
+		// latest is the last write started for serialized identifier, while in flight.
+		latest map[string]*pendingWrite
+	}
+
+	pendingWrite struct {
+		raw     []byte
+		done    chan struct{}
+		err     error
+		callers int
+	}
+)
+
+func NewCoalesceWrites[T Entity[K], K Identifier](next Repository[T, K], identitySerializer serializer[K], entitySerializer serializer[T]) *CoalesceWrites[T, K] {
+	return &CoalesceWrites[T, K]{
+		Next:                 next,
+		identifierSerializer: identitySerializer,
+		entitySerializer:     entitySerializer,
+		latest:               make(map[string]*pendingWrite),
+	}
+}
+
+func (c *CoalesceWrites[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return c.Next.Get(ctx, id)
+}
+
+// Set waits for the write it joined until its context is done.
+func (c *CoalesceWrites[T, K]) Set(ctx context.Context, entity T) error {
+	key, err := c.identifierSerializer.Serialize(entity.Identifier())
+	if err != nil {
+		return fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	raw, err := c.entitySerializer.Serialize(entity)
+	if err != nil {
+		return fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	c.lock.Lock()
+	if write, exists := c.latest[string(key)]; exists && bytes.Equal(write.raw, raw) {
+		write.callers++
+		c.lock.Unlock()
+		select {
+		case <-write.done:
+			return write.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	write := &pendingWrite{raw: raw, done: make(chan struct{}), callers: 1}
+	c.latest[string(key)] = write
+	c.lock.Unlock()
+
+	ctx, cancel := detach(ctx)
+	defer cancel()
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			write.err = fmt.Errorf("%w: %v", middlewarebuilder.ErrFlightPanicked, recovered)
+		}
+		c.lock.Lock()
+		if c.latest[string(key)] == write {
+			delete(c.latest, string(key))
+		}
+		c.lock.Unlock()
+		close(write.done)
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+	write.err = c.Next.Set(ctx, entity)
+	return write.err
+}
+
+func (c *CoalesceWrites[T, K]) Delete(ctx context.Context, id K) error {
+	return c.Next.Delete(ctx, id)
+}
+
+// detach returns context not cancelled with ctx, but still bounded by its deadline.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
@@ -0,0 +1,193 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/jlisicki/middlewarebuilder"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCoalesceWrites_Set(t *testing.T) {
+	t.Run("Should perform single downstream Set for identical concurrent Sets", func(t *testing.T) {
+		const writers = 10
+		release := make(chan struct{})
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			<-release
+			return nil
+		}}
+		repo := NewCoalesceWrites[User, UserID](next, userIDSerializer{}, userSerializer{})
+
+		var wg sync.WaitGroup
+		for i := 0; i < writers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := repo.Set(context.Background(), User{ID: "10", Name: "John"}); err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+			}()
+		}
+		for !allWritersJoined(repo, "10", writers) {
+			runtime.Gosched()
+		}
+		close(release)
+		wg.Wait()
+
+		if calls := next.count("Set"); calls != 1 {
+			t.Errorf("Got %d downstream Sets but expected 1", calls)
+		}
+	})
+	t.Run("Should not coalesce concurrent Sets with different payloads", func(t *testing.T) {
+		started := make(chan string, 2)
+		release := make(chan struct{})
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			started <- entity.Name
+			<-release
+			return nil
+		}}
+		repo := NewCoalesceWrites[User, UserID](next, userIDSerializer{}, userSerializer{})
+		var wg sync.WaitGroup
+		for _, name := range []string{"John", "Jane"} {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				_ = repo.Set(context.Background(), User{ID: "10", Name: name})
+			}(name)
+		}
+		for i := 0; i < 2; i++ {
+			select {
+			case <-started:
+			case <-time.After(time.Second):
+				t.Fatal("Expected both downstream Sets in flight")
+			}
+		}
+		close(release)
+		wg.Wait()
+
+		if calls := next.count("Set"); calls != 2 {
+			t.Errorf("Got %d downstream Sets but expected 2", calls)
+		}
+	})
+	t.Run("Should not join write overridden by a later one", func(t *testing.T) {
+		started := make(chan string)
+		release := make(chan struct{})
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			started <- entity.Name
+			<-release
+			return nil
+		}}
+		repo := NewCoalesceWrites[User, UserID](next, userIDSerializer{}, userSerializer{})
+		var wg sync.WaitGroup
+		for _, name := range []string{"John", "Jane", "John"} {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				_ = repo.Set(context.Background(), User{ID: "10", Name: name})
+			}(name)
+			select {
+			case got := <-started:
+				if got != name {
+					t.Errorf("Got downstream Set of %s but expected %s", got, name)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Expected downstream Set of %s", name)
+			}
+		}
+		close(release)
+		wg.Wait()
+
+		if calls := next.count("Set"); calls != 3 {
+			t.Errorf("Got %d downstream Sets but expected 3", calls)
+		}
+	})
+	t.Run("Should complete shared write when first writer cancels", func(t *testing.T) {
+		release := make(chan struct{})
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			<-release
+			return ctx.Err()
+		}}
+		repo := NewCoalesceWrites[User, UserID](next, userIDSerializer{}, userSerializer{})
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error)
+		go func() {
+			first <- repo.Set(ctx, User{ID: "10", Name: "John"})
+		}()
+		joined := make(chan error)
+		for !allWritersJoined(repo, "10", 1) {
+			runtime.Gosched()
+		}
+		go func() {
+			joined <- repo.Set(context.Background(), User{ID: "10", Name: "John"})
+		}()
+		for !allWritersJoined(repo, "10", 2) {
+			runtime.Gosched()
+		}
+		cancel()
+		close(release)
+		if err := <-joined; err != nil {
+			t.Errorf("Unexpected error of joined writer: %s", err)
+		}
+		if err := <-first; err != nil {
+			t.Errorf("Unexpected error of first writer: %s", err)
+		}
+	})
+	t.Run("Should keep deadline of first writer", func(t *testing.T) {
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			if _, ok := ctx.Deadline(); !ok {
+				return errExample
+			}
+			return nil
+		}}
+		repo := NewCoalesceWrites[User, UserID](next, userIDSerializer{}, userSerializer{})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		if err := repo.Set(ctx, User{ID: "10"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should report panic of shared write to joined writers", func(t *testing.T) {
+		release := make(chan struct{})
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			<-release
+			panic("broken")
+		}}
+		repo := NewCoalesceWrites[User, UserID](next, userIDSerializer{}, userSerializer{})
+		panicked := make(chan any)
+		go func() {
+			defer func() {
+				panicked <- recover()
+			}()
+			_ = repo.Set(context.Background(), User{ID: "10"})
+		}()
+		for !allWritersJoined(repo, "10", 1) {
+			runtime.Gosched()
+		}
+		joined := make(chan error)
+		go func() {
+			joined <- repo.Set(context.Background(), User{ID: "10"})
+		}()
+		for !allWritersJoined(repo, "10", 2) {
+			runtime.Gosched()
+		}
+		close(release)
+		if err := <-joined; !errors.Is(err, middlewarebuilder.ErrFlightPanicked) {
+			t.Errorf("Expected flight panicked error but got: %v", err)
+		}
+		if recovered := <-panicked; recovered != "broken" {
+			t.Errorf("Got %v but expected panic propagated to the first writer", recovered)
+		}
+		if _, exists := repo.latest["10"]; exists {
+			t.Error("Expected write to be removed after panic")
+		}
+	})
+}
+
+func allWritersJoined(repo *CoalesceWrites[User, UserID], id UserID, writers int) bool {
+	repo.lock.Lock()
+	defer repo.lock.Unlock()
+	write, exists := repo.latest[string(id)]
+	return exists && write.callers == writers
+}
@@ -0,0 +1,154 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+type (
+	// Debounce collapses Sets of an entity within Window into a single downstream Set of the latest one,
+	// made once the window expires or on Close. Gets return entities not written yet.
+	// Sets succeed immediately; errors of deferred writes are passed to OnError and the writes retried after Window.
+	Debounce[T Entity[K], K Identifier] struct {
+		Next    Repository[T, K]
+		Window  time.Duration
+		OnError func(id K, err error)
+		lock    sync.Mutex
+		// flushLock keeps deferred writes and Deletes in order.
+		flushLock sync.Mutex
+		pending   map[K]*debouncedWrite[T]
+		// scheduled counts armed timers along with writes they started.
+		scheduled sync.WaitGroup
+		closed    bool
+	}
+
+	debouncedWrite[T any] struct {
+		entity  T
+		version int
+		// timer is nil while the write is being flushed.
+		timer *time.Timer
+	}
+)
+
+// ErrDebounceClosed is returned by Set of a closed Debounce.
+var ErrDebounceClosed = errors.New("debounce is closed")
+
+func NewDebounce[T Entity[K], K Identifier](next Repository[T, K], window time.Duration) *Debounce[T, K] {
+	return &Debounce[T, K]{
+		Next:    next,
+		Window:  window,
+		pending: make(map[K]*debouncedWrite[T]),
+	}
+}
+
+func (d *Debounce[T, K]) Get(ctx context.Context, id K) (T, error) {
+	d.lock.Lock()
+	if write, exists := d.pending[id]; exists {
+		d.lock.Unlock()
+		return write.entity, nil
+	}
+	d.lock.Unlock()
+	return d.Next.Get(ctx, id)
+}
+
+func (d *Debounce[T, K]) Set(ctx context.Context, entity T) error {
+	id := entity.Identifier()
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.closed {
+		return ErrDebounceClosed
+	}
+	write, exists := d.pending[id]
+	if !exists {
+		write = &debouncedWrite[T]{}
+		d.pending[id] = write
+	}
+	write.entity = entity
+	write.version++
+	if write.timer == nil {
+		d.schedule(id, write)
+	}
+	return nil
+}
+
+// Delete drops pending write of the entity before deleting it downstream.
+func (d *Debounce[T, K]) Delete(ctx context.Context, id K) error {
+	d.flushLock.Lock()
+	defer d.flushLock.Unlock()
+	d.lock.Lock()
+	if write, exists := d.pending[id]; exists {
+		if write.timer != nil && write.timer.Stop() {
+			d.scheduled.Done()
+		}
+		delete(d.pending, id)
+	}
+	d.lock.Unlock()
+	return d.Next.Delete(ctx, id)
+}
+
+// Close writes all pending entities and waits for writes in progress, returning errors of failed writes.
+// Entities failed to be written stay pending, but aren't retried anymore.
+func (d *Debounce[T, K]) Close() error {
+	d.lock.Lock()
+	d.closed = true
+	ids := make([]K, 0, len(d.pending))
+	for id, write := range d.pending {
+		if write.timer != nil && write.timer.Stop() {
+			d.scheduled.Done()
+			ids = append(ids, id)
+		}
+	}
+	d.lock.Unlock()
+	var errs []error
+	for _, id := range ids {
+		if err := d.write(id); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	d.scheduled.Wait()
+	return errors.Join(errs...)
+}
+
+// schedule writes entity once Window expires. It must be called with lock held.
+func (d *Debounce[T, K]) schedule(id K, write *debouncedWrite[T]) {
+	d.scheduled.Add(1)
+	write.timer = time.AfterFunc(d.Window, func() {
+		defer d.scheduled.Done()
+		if err := d.write(id); err != nil && d.OnError != nil {
+			d.OnError(id, err)
+		}
+	})
+}
+
+// write stores pending entity downstream. The entity stays pending until written, unless set again meanwhile.
+// A failed write is scheduled again, unless Debounce is closed.
+func (d *Debounce[T, K]) write(id K) error {
+	d.flushLock.Lock()
+	defer d.flushLock.Unlock()
+	d.lock.Lock()
+	write, exists := d.pending[id]
+	if !exists {
+		d.lock.Unlock()
+		return nil
+	}
+	entity, version := write.entity, write.version
+	write.timer = nil
+	d.lock.Unlock()
+
+	err := d.Next.Set(context.Background(), entity)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.pending[id] != write {
+		return err
+	}
+	switch {
+	case err != nil && write.timer == nil && !d.closed:
+		d.schedule(id, write)
+	case err == nil && write.version == version:
+		delete(d.pending, id)
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDebounce(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should collapse rapid Sets into single write of the latest entity", func(t *testing.T) {
+		var lock sync.Mutex
+		var written []User
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			lock.Lock()
+			defer lock.Unlock()
+			written = append(written, entity)
+			return nil
+		}}
+		repo := NewDebounce[User, UserID](next, time.Hour)
+		for i := 1; i <= 10; i++ {
+			_ = repo.Set(ctx, User{ID: "10", Name: fmt.Sprint(i)})
+		}
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_ = repo.Set(ctx, User{ID: UserID(fmt.Sprint(20 + i))})
+				if user, _ := repo.Get(ctx, "10"); user.Name != "10" {
+					t.Errorf("Got %s but expected latest entity", user.Name)
+				}
+			}(i)
+		}
+		wg.Wait()
+		if calls := next.count("Set"); calls != 0 {
+			t.Errorf("Got %d downstream Sets before window expiry but expected none", calls)
+		}
+
+		if err := repo.Close(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if len(written) != 11 {
+			t.Fatalf("Got %d downstream Sets but expected 11", len(written))
+		}
+		for _, user := range written {
+			if user.ID == "10" && user.Name != "10" {
+				t.Errorf("Got %s written but expected latest entity", user.Name)
+			}
+		}
+	})
+	t.Run("Should write once window expires", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := NewDebounce[User, UserID](next, 10*time.Millisecond)
+		_ = repo.Set(ctx, User{ID: "10", Name: "1"})
+		_ = repo.Set(ctx, User{ID: "10", Name: "2"})
+		deadline := time.Now().Add(time.Second)
+		for next.count("Set") == 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+		if calls := next.count("Set"); calls != 1 {
+			t.Errorf("Got %d downstream Sets but expected 1", calls)
+		}
+	})
+	t.Run("Should drop pending write of deleted entity", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := NewDebounce[User, UserID](next, time.Hour)
+		_ = repo.Set(ctx, User{ID: "10"})
+		_ = repo.Delete(ctx, "10")
+		_ = repo.Close()
+		if calls := next.count("Set"); calls != 0 {
+			t.Errorf("Got %d downstream Sets but expected none", calls)
+		}
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+	})
+	t.Run("Should report errors of deferred writes", func(t *testing.T) {
+		repo := NewDebounce[User, UserID](&stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			return errExample
+		}}, time.Hour)
+		_ = repo.Set(ctx, User{ID: "10"})
+		if err := repo.Close(); !errors.Is(err, errExample) {
+			t.Errorf("Expected example error but got: %v", err)
+		}
+	})
+	t.Run("Should keep failed write pending and retry it", func(t *testing.T) {
+		var failures atomic.Int32
+		failures.Store(2)
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			if failures.Add(-1) >= 0 {
+				return errExample
+			}
+			return nil
+		}}
+		repo := NewDebounce[User, UserID](next, time.Millisecond)
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		deadline := time.Now().Add(time.Second)
+		for next.count("Set") < 3 && time.Now().Before(deadline) {
+			if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "John" {
+				t.Fatalf("Got %v, %v but expected pending entity", user, err)
+			}
+			time.Sleep(time.Millisecond)
+		}
+		if err := repo.Close(); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if calls := next.count("Set"); calls != 3 {
+			t.Errorf("Got %d downstream Sets but expected 3", calls)
+		}
+		if len(repo.pending) != 0 {
+			t.Errorf("Got %d pending writes but expected none", len(repo.pending))
+		}
+	})
+	t.Run("Should wait for fired writes on Close", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			close(started)
+			<-release
+			return nil
+		}}
+		repo := NewDebounce[User, UserID](next, time.Millisecond)
+		_ = repo.Set(ctx, User{ID: "10"})
+		<-started
+		closed := make(chan error)
+		go func() {
+			closed <- repo.Close()
+		}()
+		select {
+		case <-closed:
+			t.Fatal("Expected Close to wait for write in progress")
+		case <-time.After(10 * time.Millisecond):
+		}
+		close(release)
+		if err := <-closed; err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should reject Sets after Close", func(t *testing.T) {
+		repo := NewDebounce[User, UserID](&stubRepository{}, time.Hour)
+		_ = repo.Close()
+		if err := repo.Set(ctx, User{ID: "10"}); !errors.Is(err, ErrDebounceClosed) {
+			t.Errorf("Expected debounce closed error but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebug_Limit(t *testing.T) {
+	ctx := ContextWithEnabledDebug(context.Background())
+	t.Run("Should drop lines above the rate", func(t *testing.T) {
+		var out bytes.Buffer
+		limit := NewDebugLimit(1, 2)
+		now := time.Now()
+		limit.now = func() time.Time {
+			return now
+		}
+		repo := Debug[User, UserID]{Next: &stubRepository{}, Output: &out, Label: "Limited", Limit: limit}
+		for i := 0; i < 5; i++ {
+			_, _ = repo.Get(ctx, "10")
+		}
+		if lines := strings.Count(out.String(), "\n"); lines != 2 {
+			t.Errorf("Got %d lines but expected 2", lines)
+		}
+		if dropped := repo.Dropped(); dropped != 3 {
+			t.Errorf("Got %d dropped lines but expected 3", dropped)
+		}
+
+		now = now.Add(time.Second)
+		_ = repo.Delete(ctx, "10")
+		_ = repo.Delete(ctx, "10")
+		if lines := strings.Count(out.String(), "\n"); lines != 3 {
+			t.Errorf("Got %d lines but expected 3 after refill", lines)
+		}
+		if dropped := repo.Dropped(); dropped != 4 {
+			t.Errorf("Got %d dropped lines but expected 4", dropped)
+		}
+	})
+	t.Run("Should print every line at infinite rate", func(t *testing.T) {
+		var out bytes.Buffer
+		repo := Debug[User, UserID]{Next: &stubRepository{}, Output: &out, Label: "Unlimited", Limit: NewDebugLimit(math.Inf(1), 1)}
+		for i := 0; i < 100; i++ {
+			_, _ = repo.Get(ctx, "10")
+		}
+		if lines := strings.Count(out.String(), "[DEBUG][Unlimited] PreGet\n"); lines != 100 {
+			t.Errorf("Got %d lines but expected 100", lines)
+		}
+		if dropped := repo.Dropped(); dropped != 0 {
+			t.Errorf("Got %d dropped lines but expected none", dropped)
+		}
+	})
+}
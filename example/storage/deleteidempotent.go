@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// DeleteIdempotent makes Delete of absent entities succeed for backends reporting them as not found.
+// Existence is checked with Get, so absent entities aren't deleted at all.
+type DeleteIdempotent[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+}
+
+func (d DeleteIdempotent[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return d.Next.Get(ctx, id)
+}
+
+func (d DeleteIdempotent[T, K]) Set(ctx context.Context, entity T) error {
+	return d.Next.Set(ctx, entity)
+}
+
+func (d DeleteIdempotent[T, K]) Delete(ctx context.Context, id K) error {
+	_, err := d.Next.Get(ctx, id)
+	if errors.Is(err, errNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := d.Next.Delete(ctx, id); err != nil && !errors.Is(err, errNotFound) {
+		return err
+	}
+	return nil
+}
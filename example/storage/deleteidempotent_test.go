@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDeleteIdempotent_Delete(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func(deleteErr error) (DeleteIdempotent[User, UserID], *stubRepository) {
+		storage := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		_ = storage.Set(ctx, User{ID: "10"})
+		next := &stubRepository{
+			getFunc: storage.Get,
+			deleteFunc: func(ctx context.Context, id UserID) error {
+				if _, err := storage.Get(ctx, id); err != nil {
+					return err
+				}
+				if deleteErr != nil {
+					return deleteErr
+				}
+				return storage.Delete(ctx, id)
+			},
+		}
+		return DeleteIdempotent[User, UserID]{Next: next}, next
+	}
+
+	t.Run("Should delete present entity", func(t *testing.T) {
+		repo, next := newRepo(nil)
+		if err := repo.Delete(ctx, "10"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if _, err := next.Get(ctx, "10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected deleted entity but got: %v", err)
+		}
+	})
+	t.Run("Should succeed for absent entity", func(t *testing.T) {
+		repo, next := newRepo(nil)
+		if err := repo.Delete(ctx, "11"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if next.count("Delete") != 0 {
+			t.Error("Unexpected downstream Delete of absent entity")
+		}
+	})
+	t.Run("Should return other errors", func(t *testing.T) {
+		repo, _ := newRepo(errExample)
+		if err := repo.Delete(ctx, "10"); !errors.Is(err, errExample) {
+			t.Errorf("Expected example error but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+type (
+	// MiddlewareInfo describes a repository middleware and its settings.
+	MiddlewareInfo struct {
+		Name   string
+		Params map[string]string
+	}
+
+	// Describable is implemented by repositories describing their settings, see DescribeChain.
+	Describable interface {
+		Describe() MiddlewareInfo
+	}
+)
+
+// DescribeChain describes repositories of a chain starting at repo, following their Next fields.
+// Repositories not implementing Describable are described by their type name only.
+// Walking stops at a repository already described, so a cyclic chain is described once.
+func DescribeChain[T Entity[K], K Identifier](repo Repository[T, K]) []MiddlewareInfo {
+	var chain []MiddlewareInfo
+	visited := map[uintptr]bool{}
+	for repo != nil {
+		if v := reflect.ValueOf(repo); v.Kind() == reflect.Pointer {
+			if visited[v.Pointer()] {
+				break
+			}
+			visited[v.Pointer()] = true
+		}
+		if d, ok := repo.(Describable); ok {
+			chain = append(chain, d.Describe())
+		} else {
+			chain = append(chain, MiddlewareInfo{Name: fmt.Sprintf("%T", repo)})
+		}
+		repo = nextOf[T, K](repo)
+	}
+	return chain
+}
+
+// nextOf returns repository in Next field of repo, if any.
+func nextOf[T Entity[K], K Identifier](repo Repository[T, K]) Repository[T, K] {
+	v := reflect.ValueOf(repo)
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	field := v.FieldByName("Next")
+	if !field.IsValid() || !field.CanInterface() {
+		return nil
+	}
+	next, _ := field.Interface().(Repository[T, K])
+	return next
+}
+
+func (c *Cache[T, K]) Describe() MiddlewareInfo {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return MiddlewareInfo{Name: "Cache", Params: map[string]string{
+		"entries":    strconv.Itoa(len(c.cached)),
+		"maxEntries": strconv.Itoa(c.MaxEntries),
+		"filtered":   strconv.FormatBool(c.Cacheable != nil),
+		"interned":   strconv.FormatBool(c.Intern != nil),
+	}}
+}
+
+func (t Telemetry[T, K]) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: "Telemetry", Params: map[string]string{
+		"keyBuckets": strconv.FormatBool(t.KeyBucketer != nil),
+	}}
+}
+
+func (d Debug[T, K]) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: "Debug", Params: map[string]string{
+		"label":   d.Label,
+		"limited": strconv.FormatBool(d.Limit != nil),
+	}}
+}
+
+func (i *InMemoryRepository[T, K]) Describe() MiddlewareInfo {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return MiddlewareInfo{Name: "InMemoryRepository", Params: map[string]string{
+		"entities": strconv.Itoa(len(i.entities)),
+	}}
+}
+
+func (s SoftTimeout[T, K]) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: "SoftTimeout", Params: map[string]string{
+		"timeout": s.Timeout.String(),
+	}}
+}
+
+func (d DynamicTimeout[T, K]) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: "DynamicTimeout", Params: map[string]string{
+		"timeout": d.Timeout.String(),
+	}}
+}
+
+func (b *Bulkhead[T, K]) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: "Bulkhead", Params: map[string]string{
+		"maxConcurrent": strconv.Itoa(cap(b.slots)),
+		"queueTimeout":  b.QueueTimeout.String(),
+	}}
+}
+
+func (l KeyLimit[T, K]) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: "KeyLimit", Params: map[string]string{
+		"maxKeyBytes": strconv.Itoa(l.MaxKeyBytes),
+	}}
+}
+
+func (s SlowLog[T, K]) Describe() MiddlewareInfo {
+	return MiddlewareInfo{Name: "SlowLog", Params: map[string]string{
+		"threshold": s.Threshold.String(),
+	}}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDescribeChain(t *testing.T) {
+	t.Run("Should describe built stack", func(t *testing.T) {
+		repo := newQuietUserRepository(t)
+		_ = repo.Set(context.Background(), User{ID: "10"})
+		_, _ = repo.Get(context.Background(), "10")
+
+		expected := []MiddlewareInfo{
+			{Name: "Telemetry", Params: map[string]string{"keyBuckets": "false"}},
+			{Name: "Debug", Params: map[string]string{"label": "CacheCall", "limited": "false"}},
+			{Name: "Cache", Params: map[string]string{"entries": "1", "maxEntries": "1000", "filtered": "false", "interned": "false"}},
+			{Name: "Debug", Params: map[string]string{"label": "StorageCall", "limited": "false"}},
+			{Name: "InMemoryRepository", Params: map[string]string{"entities": "1"}},
+		}
+		if got := DescribeChain[User, UserID](repo); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Got %v but expected %v", got, expected)
+		}
+	})
+	t.Run("Should describe repositories without description by type", func(t *testing.T) {
+		repo := SlowLog[User, UserID]{Next: ReadOnly[User, UserID]{Next: &stubRepository{}}, Threshold: time.Second}
+		expected := []MiddlewareInfo{
+			{Name: "SlowLog", Params: map[string]string{"threshold": "1s"}},
+			{Name: "storage.ReadOnly[github.com/jlisicki/middlewarebuilder/example/storage.User,github.com/jlisicki/middlewarebuilder/example/storage.UserID]"},
+			{Name: "*storage.stubRepository"},
+		}
+		if got := DescribeChain[User, UserID](repo); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Got %v but expected %v", got, expected)
+		}
+	})
+	t.Run("Should stop at repeated repository", func(t *testing.T) {
+		repo := &SlowLog[User, UserID]{Threshold: time.Second}
+		repo.Next = repo
+		expected := []MiddlewareInfo{
+			{Name: "SlowLog", Params: map[string]string{"threshold": "1s"}},
+		}
+		if got := DescribeChain[User, UserID](repo); !reflect.DeepEqual(got, expected) {
+			t.Errorf("Got %v but expected %v", got, expected)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Drain rejects new operations once shut down, letting operations in flight complete.
+type Drain[T Entity[K], K Identifier] struct {
+	Next     Repository[T, K]
+	lock     sync.Mutex
+	draining bool
+	inFlight sync.WaitGroup
+}
+
+// ErrShuttingDown is returned by Drain for operations started after Shutdown.
+var ErrShuttingDown = errors.New("repository is shutting down")
+
+func (d *Drain[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if err := d.enter(); err != nil {
+		var entity T
+		return entity, err
+	}
+	defer d.inFlight.Done()
+	return d.Next.Get(ctx, id)
+}
+
+func (d *Drain[T, K]) Set(ctx context.Context, entity T) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.inFlight.Done()
+	return d.Next.Set(ctx, entity)
+}
+
+func (d *Drain[T, K]) Delete(ctx context.Context, id K) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.inFlight.Done()
+	return d.Next.Delete(ctx, id)
+}
+
+// Shutdown rejects new operations and waits until operations in flight complete or ctx is done.
+func (d *Drain[T, K]) Shutdown(ctx context.Context) error {
+	d.lock.Lock()
+	d.draining = true
+	d.lock.Unlock()
+	drained := make(chan struct{})
+	go func() {
+		d.inFlight.Wait()
+		close(drained)
+	}()
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (d *Drain[T, K]) enter() error {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	if d.draining {
+		return ErrShuttingDown
+	}
+	d.inFlight.Add(1)
+	return nil
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDrain_Shutdown(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should reject new operations while in-flight ones complete", func(t *testing.T) {
+		started, release := make(chan struct{}, 2), make(chan struct{})
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			started <- struct{}{}
+			<-release
+			return User{ID: id}, nil
+		}}
+		repo := &Drain[User, UserID]{Next: next}
+		results := make(chan error, 2)
+		for _, id := range []UserID{"10", "11"} {
+			go func(id UserID) {
+				_, err := repo.Get(ctx, id)
+				results <- err
+			}(id)
+		}
+		<-started
+		<-started
+
+		shutdown := make(chan error)
+		go func() {
+			shutdown <- repo.Shutdown(ctx)
+		}()
+		for {
+			if err := repo.Set(ctx, User{ID: "12"}); errors.Is(err, ErrShuttingDown) {
+				break
+			}
+			time.Sleep(time.Millisecond)
+		}
+		select {
+		case err := <-shutdown:
+			t.Fatalf("Shutdown returned %v before in-flight operations completed", err)
+		default:
+		}
+
+		close(release)
+		for i := 0; i < 2; i++ {
+			if err := <-results; err != nil {
+				t.Errorf("Unexpected error of in-flight operation: %s", err)
+			}
+		}
+		if err := <-shutdown; err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, ErrShuttingDown) {
+			t.Errorf("Expected shutting down error but got: %v", err)
+		}
+	})
+	t.Run("Should stop waiting when context is done", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		defer close(release)
+		repo := &Drain[User, UserID]{Next: &stubRepository{deleteFunc: func(ctx context.Context, id UserID) error {
+			close(started)
+			<-release
+			return nil
+		}}}
+		go func() {
+			_ = repo.Delete(ctx, "10")
+		}()
+		<-started
+		shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		if err := repo.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded error but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DynamicTimeout bounds Set with timeout scaled by serialized entity size
+// and other operations with a fixed timeout.
+type DynamicTimeout[T Entity[K], K Identifier] struct {
+	Next             Repository[T, K]
+	entitySerializer serializer[T]
+	// Timeout of Get and Delete.
+	Timeout time.Duration
+	// SetTimeout returns timeout of Set for entity of given serialized size.
+	SetTimeout func(bytes int) time.Duration
+}
+
+func NewDynamicTimeout[T Entity[K], K Identifier](next Repository[T, K], entitySerializer serializer[T], timeout time.Duration, setTimeout func(bytes int) time.Duration) DynamicTimeout[T, K] {
+	return DynamicTimeout[T, K]{
+		Next:             next,
+		entitySerializer: entitySerializer,
+		Timeout:          timeout,
+		SetTimeout:       setTimeout,
+	}
+}
+
+func (d DynamicTimeout[T, K]) Get(ctx context.Context, id K) (T, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+	return d.Next.Get(ctx, id)
+}
+
+func (d DynamicTimeout[T, K]) Set(ctx context.Context, entity T) error {
+	raw, err := d.entitySerializer.Serialize(entity)
+	if err != nil {
+		return fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d.SetTimeout(len(raw)))
+	defer cancel()
+	return d.Next.Set(ctx, entity)
+}
+
+func (d DynamicTimeout[T, K]) Delete(ctx context.Context, id K) error {
+	ctx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+	return d.Next.Delete(ctx, id)
+}
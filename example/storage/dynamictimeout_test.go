@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDynamicTimeout(t *testing.T) {
+	var deadline time.Time
+	next := &stubRepository{
+		getFunc: func(ctx context.Context, id UserID) (User, error) {
+			deadline, _ = ctx.Deadline()
+			return User{}, nil
+		},
+		setFunc: func(ctx context.Context, entity User) error {
+			deadline, _ = ctx.Deadline()
+			return nil
+		},
+	}
+	repo := NewDynamicTimeout[User, UserID](next, userSerializer{}, time.Second, func(bytes int) time.Duration {
+		return time.Duration(bytes) * time.Second
+	})
+
+	t.Run("Should scale Set timeout with entity size", func(t *testing.T) {
+		sT := time.Now()
+		_ = repo.Set(context.Background(), User{ID: "10"})
+		small := deadline.Sub(sT)
+		_ = repo.Set(context.Background(), User{ID: "10", Name: strings.Repeat("x", 1000)})
+		large := deadline.Sub(sT)
+		if small < 20*time.Second || small > 30*time.Second {
+			t.Errorf("Got small entity timeout %s but expected around 22s", small)
+		}
+		if large < 1000*time.Second {
+			t.Errorf("Got large entity timeout %s but expected over 1000s", large)
+		}
+	})
+	t.Run("Should use fixed timeout for reads", func(t *testing.T) {
+		sT := time.Now()
+		_, _ = repo.Get(context.Background(), "10")
+		if timeout := deadline.Sub(sT); timeout < time.Second || timeout > 2*time.Second {
+			t.Errorf("Got timeout %s but expected 1s", timeout)
+		}
+	})
+	t.Run("Should cancel slow operation", func(t *testing.T) {
+		slow := NewDynamicTimeout[User, UserID](&stubRepository{deleteFunc: func(ctx context.Context, id UserID) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}, userSerializer{}, time.Millisecond, nil)
+		if err := slow.Delete(context.Background(), "10"); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("Expected deadline exceeded error but got: %v", err)
+		}
+	})
+}
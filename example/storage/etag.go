@@ -0,0 +1,80 @@
+package storage
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ETag derives entity tags from serialized entities for conditional GET.
+type ETag[T Entity[K], K Identifier] struct {
+	entitySerializer serializer[T]
+	// Hash of serialized entity. Defaults to SHA-256.
+	Hash func([]byte) []byte
+}
+
+func NewETag[T Entity[K], K Identifier](entitySerializer serializer[T]) ETag[T, K] {
+	return ETag[T, K]{entitySerializer: entitySerializer}
+}
+
+// Compute returns quoted entity tag of entity.
+func (e ETag[T, K]) Compute(entity T) (string, error) {
+	raw, err := e.entitySerializer.Serialize(entity)
+	if err != nil {
+		return "", fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	return e.tag(raw), nil
+}
+
+// Handler serves serialized entities of repo identified by id, responding 304
+// when If-None-Match of request matches the current entity tag.
+func (e ETag[T, K]) Handler(repo Repository[T, K], id func(*http.Request) K) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		entity, err := repo.Get(request.Context(), id(request))
+		if errors.Is(err, errNotFound) {
+			http.Error(writer, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		raw, err := e.entitySerializer.Serialize(entity)
+		if err != nil {
+			http.Error(writer, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		tag := e.tag(raw)
+		writer.Header().Set("ETag", tag)
+		if matchesETag(request.Header.Get("If-None-Match"), tag) {
+			writer.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = writer.Write(raw)
+	})
+}
+
+func (e ETag[T, K]) tag(raw []byte) string {
+	var sum []byte
+	if e.Hash != nil {
+		sum = e.Hash(raw)
+	} else {
+		sha := sha256.Sum256(raw)
+		sum = sha[:]
+	}
+	return `"` + hex.EncodeToString(sum) + `"`
+}
+
+// matchesETag reports whether If-None-Match header lists tag, comparing weakly.
+func matchesETag(ifNoneMatch, tag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == tag {
+			return true
+		}
+	}
+	return false
+}
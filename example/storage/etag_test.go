@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestETag(t *testing.T) {
+	next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+		return User{ID: id, Name: "user"}, nil
+	}}
+	etag := NewETag[User, UserID](userSerializer{})
+	handler := etag.Handler(next, func(r *http.Request) UserID {
+		return UserID(r.URL.Query().Get("id"))
+	})
+	tag, err := etag.Compute(User{ID: "10", Name: "user"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("Should respond 304 for matching ETag", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/users?id=10", nil)
+		request.Header.Set("If-None-Match", tag)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusNotModified {
+			t.Errorf("Got status %d but expected 304", recorder.Code)
+		}
+		if recorder.Body.Len() != 0 {
+			t.Errorf("Got body %q but expected none", recorder.Body.String())
+		}
+	})
+	t.Run("Should respond with entity for non-matching ETag", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/users?id=11", nil)
+		request.Header.Set("If-None-Match", tag)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		if recorder.Code != http.StatusOK || recorder.Body.Len() == 0 {
+			t.Errorf("Got status %d and body %q but expected entity", recorder.Code, recorder.Body.String())
+		}
+		if got := recorder.Header().Get("ETag"); got == "" || got == tag {
+			t.Errorf("Got ETag %s but expected a different one than %s", got, tag)
+		}
+	})
+	t.Run("Should use injected hash", func(t *testing.T) {
+		custom := NewETag[User, UserID](userSerializer{})
+		custom.Hash = func(raw []byte) []byte {
+			return []byte{byte(len(raw))}
+		}
+		got, _ := custom.Compute(User{ID: "10"})
+		raw, _ := userSerializer{}.Serialize(User{ID: "10"})
+		if expected := fmt.Sprintf(`"%02x"`, len(raw)); got != expected {
+			t.Errorf("Got %s but expected %s", got, expected)
+		}
+	})
+}
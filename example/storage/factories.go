@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"github.com/jlisicki/middlewarebuilder"
+	"io"
+	"log"
+	"time"
+)
+
+type (
+	// CacheOptions configures caches created by CacheFactory.
+	CacheOptions struct {
+		// MaxEntries bounds number of cached entities, see Cache.MaxEntries.
+		MaxEntries int
+	}
+
+	// TelemetryOptions configures telemetry created by TelemetryFactory.
+	TelemetryOptions struct {
+		// Logger receives durations. Defaults to the standard logger.
+		Logger *log.Logger
+		// Now returns current time. Defaults to time.Now.
+		Now func() time.Time
+	}
+
+	// DebugOptions configures debug middlewares created by DebugFactory.
+	DebugOptions struct {
+		Output io.Writer
+		Label  string
+		// Limit caps number of printed lines, see NewDebugLimit.
+		Limit *DebugLimit
+	}
+)
+
+// CacheFactory creates factory of Cache middlewares.
+func CacheFactory[T Entity[K], K Identifier](opts CacheOptions) middlewarebuilder.Factory[Repository[T, K]] {
+	return middlewarebuilder.Named[Repository[T, K]]("Cache", middlewarebuilder.FactoryFunc[Repository[T, K]](func(next Repository[T, K]) (Repository[T, K], error) {
+		return &Cache[T, K]{Next: next, MaxEntries: opts.MaxEntries, cached: make(map[K]T)}, nil
+	}))
+}
+
+// TelemetryFactory creates factory of Telemetry middlewares.
+func TelemetryFactory[T Entity[K], K Identifier](opts TelemetryOptions) middlewarebuilder.Factory[Repository[T, K]] {
+	return middlewarebuilder.Named[Repository[T, K]]("Telemetry", middlewarebuilder.FactoryFunc[Repository[T, K]](func(next Repository[T, K]) (Repository[T, K], error) {
+		return Telemetry[T, K]{Next: next, Logger: opts.Logger, Now: opts.Now}, nil
+	}))
+}
+
+// DebugFactory creates factory of Debug middlewares, named after their label.
+func DebugFactory[T Entity[K], K Identifier](opts DebugOptions) middlewarebuilder.Factory[Repository[T, K]] {
+	return middlewarebuilder.Named[Repository[T, K]]("Debug "+opts.Label, middlewarebuilder.FactoryFunc[Repository[T, K]](func(next Repository[T, K]) (Repository[T, K], error) {
+		return Debug[T, K]{Next: next, Output: opts.Output, Label: opts.Label, Limit: opts.Limit}, nil
+	}))
+}
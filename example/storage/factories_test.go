@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"github.com/jlisicki/middlewarebuilder"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestFactories(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should build user repository from options", func(t *testing.T) {
+		builder := middlewarebuilder.NewBuilder[UserRepository]().
+			Add(TelemetryFactory[User, UserID](TelemetryOptions{Logger: discardLogger})).
+			Add(DebugFactory[User, UserID](DebugOptions{Output: io.Discard, Label: "CacheCall"})).
+			Add(CacheFactory[User, UserID](CacheOptions{MaxEntries: 2})).
+			WithHandler(NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}))
+		if names := builder.Names(); !reflect.DeepEqual(names, []string{"Telemetry", "Debug CacheCall", "Cache"}) {
+			t.Errorf("Unexpected factory names: %v", names)
+		}
+		repo, err := builder.Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "John" {
+			t.Errorf("Got %v, %v but expected John", user, err)
+		}
+		cache := repo.(Telemetry[User, UserID]).Next.(Debug[User, UserID]).Next.(*Cache[User, UserID])
+		if cache.MaxEntries != 2 {
+			t.Errorf("Got %d max entries but expected 2", cache.MaxEntries)
+		}
+	})
+}
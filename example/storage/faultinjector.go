@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// FaultRule injects latency and an error into matching calls.
+	FaultRule struct {
+		// Op is name of matching operation: Get, Set or Delete.
+		Op string
+		// Call is 1-based number of matching invocation of Op. Zero matches every invocation.
+		Call    int
+		Latency time.Duration
+		Err     error
+	}
+
+	// FaultInjector injects faults deterministically into calls matched by Rules, e.g. to test resilience middlewares.
+	// A failing call isn't passed to Next.
+	FaultInjector[T Entity[K], K Identifier] struct {
+		Next  Repository[T, K]
+		Rules []FaultRule
+		lock  sync.Mutex
+		calls map[string]int
+	}
+)
+
+func (f *FaultInjector[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if err := f.inject(ctx, "Get"); err != nil {
+		var entity T
+		return entity, err
+	}
+	return f.Next.Get(ctx, id)
+}
+
+func (f *FaultInjector[T, K]) Set(ctx context.Context, entity T) error {
+	if err := f.inject(ctx, "Set"); err != nil {
+		return err
+	}
+	return f.Next.Set(ctx, entity)
+}
+
+func (f *FaultInjector[T, K]) Delete(ctx context.Context, id K) error {
+	if err := f.inject(ctx, "Delete"); err != nil {
+		return err
+	}
+	return f.Next.Delete(ctx, id)
+}
+
+// inject counts invocation of op and applies latency and error of the rules it matches.
+func (f *FaultInjector[T, K]) inject(ctx context.Context, op string) error {
+	f.lock.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[op]++
+	call := f.calls[op]
+	f.lock.Unlock()
+
+	var latency time.Duration
+	var err error
+	for _, rule := range f.Rules {
+		if rule.Op != op || (rule.Call != 0 && rule.Call != call) {
+			continue
+		}
+		latency += rule.Latency
+		if err == nil {
+			err = rule.Err
+		}
+	}
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return err
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFaultInjector(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should fail only matching call", func(t *testing.T) {
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id}, nil
+		}}
+		repo := &FaultInjector[User, UserID]{
+			Next:  next,
+			Rules: []FaultRule{{Op: "Get", Call: 2, Err: errExample}},
+		}
+		for i, expected := range []error{nil, errExample, nil} {
+			if _, err := repo.Get(ctx, "10"); !errors.Is(err, expected) {
+				t.Errorf("Got error %v of call %d but expected %v", err, i+1, expected)
+			}
+		}
+		if err := repo.Set(ctx, User{ID: "10"}); err != nil {
+			t.Errorf("Unexpected error of other operation: %s", err)
+		}
+		if calls := next.count("Get"); calls != 2 {
+			t.Errorf("Got %d downstream Gets but expected 2", calls)
+		}
+	})
+	t.Run("Should add latency to every matching call", func(t *testing.T) {
+		repo := &FaultInjector[User, UserID]{
+			Next:  &stubRepository{},
+			Rules: []FaultRule{{Op: "Set", Latency: 20 * time.Millisecond}},
+		}
+		sT := time.Now()
+		_ = repo.Set(ctx, User{ID: "10"})
+		_ = repo.Set(ctx, User{ID: "10"})
+		if elapsed := time.Since(sT); elapsed < 40*time.Millisecond {
+			t.Errorf("Got %s but expected at least 40ms", elapsed)
+		}
+	})
+	t.Run("Should count calls under concurrency", func(t *testing.T) {
+		repo := &FaultInjector[User, UserID]{
+			Next:  &stubRepository{},
+			Rules: []FaultRule{{Op: "Delete", Call: 50, Err: errExample}},
+		}
+		var wg sync.WaitGroup
+		var lock sync.Mutex
+		failed := 0
+		for i := 0; i < 100; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if err := repo.Delete(ctx, "10"); err != nil {
+					lock.Lock()
+					failed++
+					lock.Unlock()
+				}
+			}()
+		}
+		wg.Wait()
+		if failed != 1 {
+			t.Errorf("Got %d failed calls but expected 1", failed)
+		}
+	})
+}
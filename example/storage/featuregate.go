@@ -0,0 +1,66 @@
+package storage
+
+import "context"
+
+type (
+	// Flags tells whether a feature is enabled for a call.
+	Flags interface {
+		Enabled(ctx context.Context, flag string) bool
+	}
+
+	// FlagsFunc implements Flags interface as function.
+	FlagsFunc func(ctx context.Context, flag string) bool
+
+	// FeatureGate calls Next when Flag is enabled and Alternate otherwise, e.g. Cache and its Next to toggle caching.
+	// Flags set with ContextWithFlags take precedence over Flags provider. Flag is enabled when neither knows it.
+	FeatureGate[T Entity[K], K Identifier] struct {
+		Next      Repository[T, K]
+		Alternate Repository[T, K]
+		Flag      string
+		Flags     Flags
+	}
+)
+
+type flagsCtxKey string
+
+var flagsKey flagsCtxKey = "flags"
+
+// ContextWithFlags overrides flags of FeatureGate for calls made with returned context.
+func ContextWithFlags(ctx context.Context, flags map[string]bool) context.Context {
+	return context.WithValue(ctx, flagsKey, flags)
+}
+
+func (f FlagsFunc) Enabled(ctx context.Context, flag string) bool {
+	return f(ctx, flag)
+}
+
+func (g FeatureGate[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return g.pick(ctx).Get(ctx, id)
+}
+
+func (g FeatureGate[T, K]) Set(ctx context.Context, entity T) error {
+	return g.pick(ctx).Set(ctx, entity)
+}
+
+func (g FeatureGate[T, K]) Delete(ctx context.Context, id K) error {
+	return g.pick(ctx).Delete(ctx, id)
+}
+
+func (g FeatureGate[T, K]) pick(ctx context.Context) Repository[T, K] {
+	if g.enabled(ctx) {
+		return g.Next
+	}
+	return g.Alternate
+}
+
+func (g FeatureGate[T, K]) enabled(ctx context.Context) bool {
+	if flags, ok := ctx.Value(flagsKey).(map[string]bool); ok {
+		if enabled, known := flags[g.Flag]; known {
+			return enabled
+		}
+	}
+	if g.Flags == nil {
+		return true
+	}
+	return g.Flags.Enabled(ctx, g.Flag)
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFeatureGate(t *testing.T) {
+	ctx := context.Background()
+	newGate := func(flags Flags) (FeatureGate[User, UserID], *stubRepository) {
+		storage := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id}, nil
+		}}
+		cache := &Cache[User, UserID]{Next: storage, cached: make(map[UserID]User)}
+		return FeatureGate[User, UserID]{Next: cache, Alternate: storage, Flag: "cache", Flags: flags}, storage
+	}
+
+	t.Run("Should toggle path with provider", func(t *testing.T) {
+		enabled := true
+		gate, storage := newGate(FlagsFunc(func(ctx context.Context, flag string) bool {
+			return flag == "cache" && enabled
+		}))
+		_, _ = gate.Get(ctx, "10")
+		_, _ = gate.Get(ctx, "10")
+		if calls := storage.count("Get"); calls != 1 {
+			t.Errorf("Got %d downstream Gets but expected 1 with cache enabled", calls)
+		}
+		enabled = false
+		_, _ = gate.Get(ctx, "10")
+		if calls := storage.count("Get"); calls != 2 {
+			t.Errorf("Got %d downstream Gets but expected 2 with cache disabled", calls)
+		}
+	})
+	t.Run("Should prefer flags from context", func(t *testing.T) {
+		gate, storage := newGate(FlagsFunc(func(ctx context.Context, flag string) bool {
+			return true
+		}))
+		ctx := ContextWithFlags(ctx, map[string]bool{"cache": false})
+		_, _ = gate.Get(ctx, "10")
+		_, _ = gate.Get(ctx, "10")
+		if calls := storage.count("Get"); calls != 2 {
+			t.Errorf("Got %d downstream Gets but expected 2", calls)
+		}
+	})
+	t.Run("Should enable unknown flag", func(t *testing.T) {
+		gate, storage := newGate(nil)
+		_, _ = gate.Get(ctx, "10")
+		_, _ = gate.Get(ctx, "10")
+		if calls := storage.count("Get"); calls != 1 {
+			t.Errorf("Got %d downstream Gets but expected 1", calls)
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package storage
+
+import (
+	"context"
+	"math"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+type (
+	// HistogramStats summarizes durations of an operation.
+	HistogramStats struct {
+		Count         int64
+		P50, P95, P99 time.Duration
+	}
+
+	// HistogramTelemetry aggregates durations of operations into histograms instead of logging every call.
+	HistogramTelemetry[T Entity[K], K Identifier] struct {
+		Next Repository[T, K]
+		// Now returns current time. Defaults to time.Now.
+		Now        func() time.Time
+		lock       sync.Mutex
+		histograms map[string]*histogram
+	}
+
+	// histogram counts values in log-linear buckets, HDR-style, keeping relative error of percentiles under ~3%.
+	histogram struct {
+		count   int64
+		buckets [histogramBuckets]int64
+	}
+)
+
+const (
+	// histogramSubBuckets per power of two. Values below 2*histogramSubBuckets are counted exactly.
+	histogramSubBuckets = 16
+	histogramBuckets    = (64-5)*histogramSubBuckets + 2*histogramSubBuckets
+)
+
+func (h *HistogramTelemetry[T, K]) Get(ctx context.Context, id K) (T, error) {
+	defer h.observe("Get", h.now())
+	return h.Next.Get(ctx, id)
+}
+
+func (h *HistogramTelemetry[T, K]) Set(ctx context.Context, entity T) error {
+	defer h.observe("Set", h.now())
+	return h.Next.Set(ctx, entity)
+}
+
+func (h *HistogramTelemetry[T, K]) Delete(ctx context.Context, id K) error {
+	defer h.observe("Delete", h.now())
+	return h.Next.Delete(ctx, id)
+}
+
+// Snapshot returns stats of durations observed so far, by operation.
+func (h *HistogramTelemetry[T, K]) Snapshot() map[string]HistogramStats {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	snapshot := make(map[string]HistogramStats, len(h.histograms))
+	for op, hist := range h.histograms {
+		snapshot[op] = HistogramStats{
+			Count: hist.count,
+			P50:   time.Duration(hist.percentile(0.50)),
+			P95:   time.Duration(hist.percentile(0.95)),
+			P99:   time.Duration(hist.percentile(0.99)),
+		}
+	}
+	return snapshot
+}
+
+func (h *HistogramTelemetry[T, K]) observe(op string, sT time.Time) {
+	d := h.now().Sub(sT)
+	if d < 0 {
+		d = 0
+	}
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if h.histograms == nil {
+		h.histograms = make(map[string]*histogram)
+	}
+	hist, exists := h.histograms[op]
+	if !exists {
+		hist = &histogram{}
+		h.histograms[op] = hist
+	}
+	hist.record(uint64(d))
+}
+
+func (h *HistogramTelemetry[T, K]) now() time.Time {
+	if h.Now == nil {
+		return time.Now()
+	}
+	return h.Now()
+}
+
+func (h *histogram) record(v uint64) {
+	h.buckets[bucketIndex(v)]++
+	h.count++
+}
+
+// percentile returns value of q-th quantile, approximated by the middle of its bucket.
+func (h *histogram) percentile(q float64) uint64 {
+	rank := int64(math.Ceil(q * float64(h.count)))
+	var seen int64
+	for i, n := range h.buckets {
+		seen += n
+		if n > 0 && seen >= rank {
+			return bucketValue(i)
+		}
+	}
+	return 0
+}
+
+func bucketIndex(v uint64) int {
+	if v < 2*histogramSubBuckets {
+		return int(v)
+	}
+	shift := bits.Len64(v) - 5
+	return shift*histogramSubBuckets + int(v>>shift)
+}
+
+func bucketValue(i int) uint64 {
+	if i < 2*histogramSubBuckets {
+		return uint64(i)
+	}
+	shift := i/histogramSubBuckets - 1
+	lower := uint64(i-shift*histogramSubBuckets) << shift
+	return lower + (uint64(1)<<shift)/2
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHistogramTelemetry(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should report approximate percentiles per operation", func(t *testing.T) {
+		var lock sync.Mutex
+		var clock time.Time
+		var latency time.Duration
+		next := &stubRepository{
+			getFunc: func(ctx context.Context, id UserID) (User, error) {
+				clock = clock.Add(latency)
+				return User{}, nil
+			},
+			setFunc: func(ctx context.Context, entity User) error {
+				clock = clock.Add(time.Second)
+				return nil
+			},
+		}
+		repo := &HistogramTelemetry[User, UserID]{
+			Next: next,
+			Now: func() time.Time {
+				lock.Lock()
+				defer lock.Unlock()
+				return clock
+			},
+		}
+		for i := 1; i <= 100; i++ {
+			latency = time.Duration(i) * time.Millisecond
+			_, _ = repo.Get(ctx, "10")
+		}
+		_ = repo.Set(ctx, User{ID: "10"})
+
+		snapshot := repo.Snapshot()
+		get := snapshot["Get"]
+		if get.Count != 100 {
+			t.Errorf("Got %d Gets but expected 100", get.Count)
+		}
+		for _, p := range []struct {
+			name          string
+			got, expected time.Duration
+		}{
+			{"p50", get.P50, 50 * time.Millisecond},
+			{"p95", get.P95, 95 * time.Millisecond},
+			{"p99", get.P99, 99 * time.Millisecond},
+		} {
+			if diff := p.got - p.expected; diff < -p.expected/20 || diff > p.expected/20 {
+				t.Errorf("Got %s %s but expected about %s", p.name, p.got, p.expected)
+			}
+		}
+		if set := snapshot["Set"]; set.Count != 1 || set.P99 < 950*time.Millisecond || set.P99 > 1050*time.Millisecond {
+			t.Errorf("Got Set stats %+v but expected single 1s observation", set)
+		}
+		if _, exists := snapshot["Delete"]; exists {
+			t.Error("Expected no stats of operation never called")
+		}
+	})
+	t.Run("Should be safe for concurrent use", func(t *testing.T) {
+		repo := &HistogramTelemetry[User, UserID]{Next: &stubRepository{}}
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_ = repo.Delete(ctx, "10")
+				_ = repo.Snapshot()
+			}()
+		}
+		wg.Wait()
+		if count := repo.Snapshot()["Delete"].Count; count != 50 {
+			t.Errorf("Got %d Deletes but expected 50", count)
+		}
+	})
+}
+
+func TestHistogram_buckets(t *testing.T) {
+	t.Run("Should map bucket values back to their buckets", func(t *testing.T) {
+		for i := 0; i < histogramBuckets; i++ {
+			if got := bucketIndex(bucketValue(i)); got != i {
+				t.Fatalf("Got bucket %d of value of bucket %d", got, i)
+			}
+		}
+	})
+}
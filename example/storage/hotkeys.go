@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+)
+
+type (
+	// HotKeys estimates access frequency of keys read with Get in a count-min sketch of fixed size,
+	// keeping up to Capacity most frequently read keys as top candidates.
+	HotKeys[T Entity[K], K Identifier] struct {
+		Next                 Repository[T, K]
+		identifierSerializer serializer[K]
+		capacity             int
+		lock                 sync.Mutex
+		sketch               [hotKeysDepth][hotKeysWidth]uint32
+		top                  map[K]uint64
+	}
+
+	// KeyCount is estimated number of reads of a key.
+	KeyCount[K Identifier] struct {
+		Key   K
+		Count uint64
+	}
+)
+
+const (
+	hotKeysDepth = 4
+	hotKeysWidth = 2048
+)
+
+func NewHotKeys[T Entity[K], K Identifier](next Repository[T, K], identitySerializer serializer[K], capacity int) *HotKeys[T, K] {
+	return &HotKeys[T, K]{
+		Next:                 next,
+		identifierSerializer: identitySerializer,
+		capacity:             capacity,
+		top:                  make(map[K]uint64, capacity),
+	}
+}
+
+func (h *HotKeys[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if key, err := h.identifierSerializer.Serialize(id); err == nil {
+		h.count(id, fnvHash(key))
+	}
+	return h.Next.Get(ctx, id)
+}
+
+func (h *HotKeys[T, K]) Set(ctx context.Context, entity T) error {
+	return h.Next.Set(ctx, entity)
+}
+
+func (h *HotKeys[T, K]) Delete(ctx context.Context, id K) error {
+	return h.Next.Delete(ctx, id)
+}
+
+// Top returns up to n most frequently read keys, most frequent first. Counts are estimates never lower than actual ones.
+// It returns nil for n not greater than zero.
+func (h *HotKeys[T, K]) Top(n int) []KeyCount[K] {
+	if n <= 0 {
+		return nil
+	}
+	h.lock.Lock()
+	top := make([]KeyCount[K], 0, len(h.top))
+	for key, count := range h.top {
+		top = append(top, KeyCount[K]{Key: key, Count: count})
+	}
+	h.lock.Unlock()
+	sort.Slice(top, func(i, j int) bool {
+		return top[i].Count > top[j].Count
+	})
+	if len(top) > n {
+		top = top[:n]
+	}
+	return top
+}
+
+// count increments counters of key in every row of the sketch, updating top candidates with the estimate.
+func (h *HotKeys[T, K]) count(id K, hash uint64) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	h1, h2 := uint32(hash), uint32(hash>>32)
+	estimate := ^uint32(0)
+	for row := range h.sketch {
+		counter := &h.sketch[row][(h1+uint32(row)*h2)%hotKeysWidth]
+		*counter++
+		if *counter < estimate {
+			estimate = *counter
+		}
+	}
+	if _, isTop := h.top[id]; isTop || len(h.top) < h.capacity {
+		h.top[id] = uint64(estimate)
+		return
+	}
+	var coldest K
+	coldestCount := ^uint64(0)
+	for key, count := range h.top {
+		if count < coldestCount {
+			coldest, coldestCount = key, count
+		}
+	}
+	if uint64(estimate) > coldestCount {
+		delete(h.top, coldest)
+		h.top[id] = uint64(estimate)
+	}
+}
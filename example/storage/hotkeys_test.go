@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestHotKeys_Top(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should report hot keys of skewed distribution", func(t *testing.T) {
+		repo := NewHotKeys[User, UserID](&stubRepository{}, userIDSerializer{}, 10)
+		var wg sync.WaitGroup
+		for worker := 0; worker < 4; worker++ {
+			wg.Add(1)
+			go func(worker int) {
+				defer wg.Done()
+				for i := 0; i < 1000; i++ {
+					// Keys "hot-0".."hot-2" get most of reads, while 500 cold keys are read rarely.
+					_, _ = repo.Get(ctx, UserID(fmt.Sprintf("hot-%d", i%3)))
+					_, _ = repo.Get(ctx, UserID(fmt.Sprintf("cold-%d", (worker*1000+i)%500)))
+				}
+			}(worker)
+		}
+		wg.Wait()
+
+		top := repo.Top(3)
+		if len(top) != 3 {
+			t.Fatalf("Got %d keys but expected 3", len(top))
+		}
+		for _, kc := range top {
+			if kc.Key[:4] != "hot-" {
+				t.Errorf("Got %v in top keys but expected only hot keys", top)
+			}
+			if kc.Count < 1332 {
+				t.Errorf("Got count %d of %s but expected at least 1332", kc.Count, kc.Key)
+			}
+		}
+		if n := len(repo.Top(100)); n > 10 {
+			t.Errorf("Got %d keys but expected at most capacity of 10", n)
+		}
+		for _, n := range []int{0, -1} {
+			if top := repo.Top(n); len(top) != 0 {
+				t.Errorf("Got %v for Top(%d) but expected no keys", top, n)
+			}
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// Immutable allows only creation of entities; updates of stored ones are rejected.
+type Immutable[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+}
+
+type immutableOverrideCtxKey string
+
+var immutableOverride immutableOverrideCtxKey = "immutableOverride"
+
+// ErrImmutable is returned for updates of entities rejected by Immutable.
+var ErrImmutable = errors.New("entity is immutable")
+
+// ContextWithImmutableOverride allows admin corrections of immutable entities.
+func ContextWithImmutableOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, immutableOverride, "enabled")
+}
+
+func (i Immutable[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return i.Next.Get(ctx, id)
+}
+
+func (i Immutable[T, K]) Set(ctx context.Context, entity T) error {
+	if _, ok := ctx.Value(immutableOverride).(string); !ok {
+		_, err := i.Next.Get(ctx, entity.Identifier())
+		if err == nil {
+			return ErrImmutable
+		}
+		if !errors.Is(err, errNotFound) {
+			return err
+		}
+	}
+	return i.Next.Set(ctx, entity)
+}
+
+func (i Immutable[T, K]) Delete(ctx context.Context, id K) error {
+	return i.Next.Delete(ctx, id)
+}
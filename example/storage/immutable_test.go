@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestImmutable_Set(t *testing.T) {
+	ctx := context.Background()
+	storage := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+	repo := Immutable[User, UserID]{Next: storage}
+
+	t.Run("Should create entity", func(t *testing.T) {
+		if err := repo.Set(ctx, User{ID: "10", Name: "John"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should reject update", func(t *testing.T) {
+		if err := repo.Set(ctx, User{ID: "10", Name: "Jane"}); !errors.Is(err, ErrImmutable) {
+			t.Errorf("Expected immutable error but got: %v", err)
+		}
+		if user, _ := storage.Get(ctx, "10"); user.Name != "John" {
+			t.Errorf("Got '%s' but expected 'John'", user.Name)
+		}
+	})
+	t.Run("Should allow update with override", func(t *testing.T) {
+		if err := repo.Set(ContextWithImmutableOverride(ctx), User{ID: "10", Name: "Jane"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if user, _ := storage.Get(ctx, "10"); user.Name != "Jane" {
+			t.Errorf("Got '%s' but expected 'Jane'", user.Name)
+		}
+	})
+}
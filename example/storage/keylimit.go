@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// KeyLimit rejects identifiers longer than a backend accepts before they reach it.
+type KeyLimit[T Entity[K], K Identifier] struct {
+	Next                 Repository[T, K]
+	identifierSerializer serializer[K]
+	// MaxKeyBytes is maximal length of serialized identifier.
+	MaxKeyBytes int
+}
+
+// ErrKeyTooLong is returned by KeyLimit for identifiers over MaxKeyBytes.
+var ErrKeyTooLong = errors.New("key too long")
+
+func NewKeyLimit[T Entity[K], K Identifier](next Repository[T, K], identifierSerializer serializer[K], maxKeyBytes int) KeyLimit[T, K] {
+	return KeyLimit[T, K]{
+		Next:                 next,
+		identifierSerializer: identifierSerializer,
+		MaxKeyBytes:          maxKeyBytes,
+	}
+}
+
+func (l KeyLimit[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if err := l.check(id); err != nil {
+		var entity T
+		return entity, err
+	}
+	return l.Next.Get(ctx, id)
+}
+
+func (l KeyLimit[T, K]) Set(ctx context.Context, entity T) error {
+	if err := l.check(entity.Identifier()); err != nil {
+		return err
+	}
+	return l.Next.Set(ctx, entity)
+}
+
+func (l KeyLimit[T, K]) Delete(ctx context.Context, id K) error {
+	if err := l.check(id); err != nil {
+		return err
+	}
+	return l.Next.Delete(ctx, id)
+}
+
+func (l KeyLimit[T, K]) check(id K) error {
+	key, err := l.identifierSerializer.Serialize(id)
+	if err != nil {
+		return fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	if len(key) > l.MaxKeyBytes {
+		return fmt.Errorf("%w: %d bytes over limit of %d", ErrKeyTooLong, len(key), l.MaxKeyBytes)
+	}
+	return nil
+}
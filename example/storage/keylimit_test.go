@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestKeyLimit(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should pass keys under the limit", func(t *testing.T) {
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id}, nil
+		}}
+		repo := NewKeyLimit[User, UserID](next, userIDSerializer{}, 4)
+		if _, err := repo.Get(ctx, "1234"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if err := repo.Set(ctx, User{ID: "12"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if err := repo.Delete(ctx, "1"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if calls := len(next.calls); calls != 3 {
+			t.Errorf("Got %d downstream calls but expected 3", calls)
+		}
+	})
+	t.Run("Should reject keys over the limit before calling next", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := NewKeyLimit[User, UserID](next, userIDSerializer{}, 4)
+		if _, err := repo.Get(ctx, "12345"); !errors.Is(err, ErrKeyTooLong) {
+			t.Errorf("Expected key too long error but got: %v", err)
+		}
+		if err := repo.Set(ctx, User{ID: "12345"}); !errors.Is(err, ErrKeyTooLong) {
+			t.Errorf("Expected key too long error but got: %v", err)
+		}
+		if err := repo.Delete(ctx, "12345"); !errors.Is(err, ErrKeyTooLong) {
+			t.Errorf("Expected key too long error but got: %v", err)
+		}
+		if calls := len(next.calls); calls != 0 {
+			t.Errorf("Got %d downstream calls but expected none", calls)
+		}
+	})
+	t.Run("Should report identifier serialization errors", func(t *testing.T) {
+		repo := NewKeyLimit[User, UserID](&stubRepository{}, failingUserIDSerializer{}, 4)
+		if err := repo.Delete(ctx, "1"); !errors.Is(err, ErrSerialize) {
+			t.Errorf("Expected serialize error but got: %v", err)
+		}
+	})
+}
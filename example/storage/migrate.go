@@ -0,0 +1,87 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type (
+	// MigratingSerializer writes entities with current serializer and reads them with legacy ones
+	// when current can't. Entities read by a legacy serializer are passed to OnLegacy, see Migrate.
+	MigratingSerializer[T any] struct {
+		Current serializer[T]
+		Legacy  []serializer[T]
+		// OnLegacy is called with entities read in a legacy format, e.g. Migrate.MarkLegacy. Ignored when nil.
+		OnLegacy func(entity T)
+	}
+
+	// Migrate rewrites entities read in a legacy format. Its MarkLegacy must be set as OnLegacy of
+	// MigratingSerializer of the underlying repository. Zero value with Next set is ready to use.
+	// Entities read in a legacy format by others than Migrate are rewritten on their next Get.
+	Migrate[T Entity[K], K Identifier] struct {
+		Next   Repository[T, K]
+		lock   sync.Mutex
+		legacy map[K]struct{}
+	}
+)
+
+func (m MigratingSerializer[T]) Serialize(entity T) ([]byte, error) {
+	return m.Current.Serialize(entity)
+}
+
+func (m MigratingSerializer[T]) UnSerialize(raw []byte) (T, error) {
+	entity, err := m.Current.UnSerialize(raw)
+	if err == nil {
+		return entity, nil
+	}
+	for _, legacy := range m.Legacy {
+		if legacyEntity, legacyErr := legacy.UnSerialize(raw); legacyErr == nil {
+			if m.OnLegacy != nil {
+				m.OnLegacy(legacyEntity)
+			}
+			return legacyEntity, nil
+		}
+	}
+	return entity, err
+}
+
+// MarkLegacy records that entity was read in a legacy format, so Get rewrites it.
+func (m *Migrate[T, K]) MarkLegacy(entity T) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.legacy == nil {
+		m.legacy = make(map[K]struct{})
+	}
+	m.legacy[entity.Identifier()] = struct{}{}
+}
+
+// Get returns entities stored in a legacy format after writing them back in the current one.
+func (m *Migrate[T, K]) Get(ctx context.Context, id K) (T, error) {
+	entity, err := m.Next.Get(ctx, id)
+	if err != nil || !m.takeLegacy(id) {
+		return entity, err
+	}
+	if err := m.Next.Set(ctx, entity); err != nil {
+		m.MarkLegacy(entity)
+		return entity, fmt.Errorf("unable to migrate entity: %w", err)
+	}
+	return entity, nil
+}
+
+func (m *Migrate[T, K]) Set(ctx context.Context, entity T) error {
+	return m.Next.Set(ctx, entity)
+}
+
+func (m *Migrate[T, K]) Delete(ctx context.Context, id K) error {
+	return m.Next.Delete(ctx, id)
+}
+
+// takeLegacy reports whether id was read in a legacy format and not yet rewritten.
+func (m *Migrate[T, K]) takeLegacy(id K) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	_, legacy := m.legacy[id]
+	delete(m.legacy, id)
+	return legacy
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// legacyUserSerializer stores users as "id|name".
+type legacyUserSerializer struct{}
+
+func (l legacyUserSerializer) Serialize(u User) ([]byte, error) {
+	return []byte(string(u.ID) + "|" + u.Name), nil
+}
+
+func (l legacyUserSerializer) UnSerialize(bytes []byte) (User, error) {
+	id, name, found := strings.Cut(string(bytes), "|")
+	if !found {
+		return User{}, errors.New("invalid legacy user")
+	}
+	return User{ID: UserID(id), Name: name}, nil
+}
+
+func TestMigrate_Get(t *testing.T) {
+	ctx := context.Background()
+	newLegacyStorage := func() (*InMemoryRepository[User, UserID], *Migrate[User, UserID]) {
+		migrate := &Migrate[User, UserID]{}
+		storage := NewInMemoryRepository[User, UserID](userIDSerializer{}, MigratingSerializer[User]{
+			Current:  userSerializer{},
+			Legacy:   []serializer[User]{legacyUserSerializer{}},
+			OnLegacy: migrate.MarkLegacy,
+		})
+		storage.entities["10"], _ = legacyUserSerializer{}.Serialize(User{ID: "10", Name: "John"})
+		return storage, migrate
+	}
+	t.Run("Should read legacy entity and store it in current format", func(t *testing.T) {
+		storage, repo := newLegacyStorage()
+		repo.Next = storage
+
+		user, err := repo.Get(ctx, "10")
+		if err != nil || user.Name != "John" {
+			t.Fatalf("Got %v, %v but expected John", user, err)
+		}
+		if raw := string(storage.entities["10"]); raw != `{"ID":"10","Name":"John"}` {
+			t.Errorf("Got stored '%s' but expected current format", raw)
+		}
+	})
+	t.Run("Should return error for unreadable entity", func(t *testing.T) {
+		storage := NewInMemoryRepository[User, UserID](userIDSerializer{}, MigratingSerializer[User]{
+			Current: userSerializer{},
+			Legacy:  []serializer[User]{legacyUserSerializer{}},
+		})
+		storage.entities["10"] = []byte("unreadable")
+		_, err := (&Migrate[User, UserID]{Next: storage}).Get(ctx, "10")
+		if !errors.Is(err, ErrDeserialize) {
+			t.Errorf("Expected deserialize error but got: %v", err)
+		}
+	})
+	t.Run("Should migrate through other middlewares", func(t *testing.T) {
+		storage, repo := newLegacyStorage()
+		repo.Next = &Cache[User, UserID]{Next: storage, cached: make(map[UserID]User)}
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "John" {
+			t.Fatalf("Got %v, %v but expected John", user, err)
+		}
+		if raw := string(storage.entities["10"]); raw != `{"ID":"10","Name":"John"}` {
+			t.Errorf("Got stored '%s' but expected current format", raw)
+		}
+	})
+	t.Run("Should not rewrite entity in current format", func(t *testing.T) {
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id}, nil
+		}}
+		if _, err := (&Migrate[User, UserID]{Next: next}).Get(ctx, "10"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if calls := next.count("Set"); calls != 0 {
+			t.Errorf("Got %d downstream Sets but expected none", calls)
+		}
+	})
+	t.Run("Should read legacy entity without Migrate", func(t *testing.T) {
+		storage, _ := newLegacyStorage()
+		err := storage.Patch(ctx, "10", func(u User) (User, error) {
+			u.Name = "Jane"
+			return u, nil
+		})
+		if err != nil {
+			t.Errorf("Unexpected error of Patch: %s", err)
+		}
+		if user, err := storage.GetOrCreate(ctx, "10", func() User { return User{ID: "10"} }); err != nil || user.Name != "Jane" {
+			t.Errorf("Got %v, %v but expected Jane", user, err)
+		}
+		storage.entities["11"], _ = legacyUserSerializer{}.Serialize(User{ID: "11"})
+		if err := (DeleteIdempotent[User, UserID]{Next: storage}).Delete(ctx, "11"); err != nil {
+			t.Errorf("Unexpected error of Delete: %s", err)
+		}
+		if _, exists := storage.entities["11"]; exists {
+			t.Error("Expected legacy entity deleted")
+		}
+	})
+}
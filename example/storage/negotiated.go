@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type (
+	// NegotiatedSerializer serializes entities in a format chosen by context, see ContextWithFormat,
+	// or in Default format otherwise. Serialized entities are tagged with their format,
+	// so they're unserialized with the matching serializer regardless of context.
+	NegotiatedSerializer[T any] struct {
+		Default string
+		Formats map[string]serializer[T]
+	}
+
+	// contextSerializer is implemented by serializers choosing format by context.
+	contextSerializer[T any] interface {
+		SerializeContext(ctx context.Context, entity T) ([]byte, error)
+	}
+)
+
+type formatCtxKey string
+
+var formatKey formatCtxKey = "format"
+
+// ErrUnknownFormat is returned for formats without registered serializer.
+var ErrUnknownFormat = errors.New("unknown serialization format")
+
+// ContextWithFormat sets serialization format of entities written in context.
+func ContextWithFormat(ctx context.Context, format string) context.Context {
+	return context.WithValue(ctx, formatKey, format)
+}
+
+// Serialize entity in Default format.
+func (n NegotiatedSerializer[T]) Serialize(entity T) ([]byte, error) {
+	return n.serialize(n.Default, entity)
+}
+
+// SerializeContext serializes entity in format set in context, or in Default format when there's none.
+func (n NegotiatedSerializer[T]) SerializeContext(ctx context.Context, entity T) ([]byte, error) {
+	format, ok := ctx.Value(formatKey).(string)
+	if !ok {
+		format = n.Default
+	}
+	return n.serialize(format, entity)
+}
+
+func (n NegotiatedSerializer[T]) UnSerialize(raw []byte) (T, error) {
+	var entity T
+	if len(raw) == 0 || len(raw) < 1+int(raw[0]) {
+		return entity, errors.New("missing format tag")
+	}
+	format, payload := string(raw[1:1+raw[0]]), raw[1+raw[0]:]
+	s, exists := n.Formats[format]
+	if !exists {
+		return entity, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	return s.UnSerialize(payload)
+}
+
+// serialize entity prefixed with format tag: length of format name followed by the name.
+func (n NegotiatedSerializer[T]) serialize(format string, entity T) ([]byte, error) {
+	s, exists := n.Formats[format]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownFormat, format)
+	}
+	if len(format) > 255 {
+		return nil, fmt.Errorf("format name too long: %s", format)
+	}
+	payload, err := s.Serialize(entity)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{byte(len(format))}, format...), payload...), nil
+}
@@ -0,0 +1,50 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNegotiatedSerializer(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func() *InMemoryRepository[User, UserID] {
+		return NewInMemoryRepository[User, UserID](userIDSerializer{}, NegotiatedSerializer[User]{
+			Default: "json",
+			Formats: map[string]serializer[User]{
+				"json":   userSerializer{},
+				"legacy": legacyUserSerializer{},
+			},
+		})
+	}
+
+	t.Run("Should round-trip entity via two formats", func(t *testing.T) {
+		repo := newRepo()
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		_ = repo.Set(ContextWithFormat(ctx, "legacy"), User{ID: "11", Name: "Jane"})
+
+		if raw := string(repo.entities["10"]); !strings.Contains(raw, `"Name":"John"`) {
+			t.Errorf("Got %q but expected entity stored as JSON", raw)
+		}
+		if raw := string(repo.entities["11"]); !strings.HasSuffix(raw, "11|Jane") {
+			t.Errorf("Got %q but expected entity stored in legacy format", raw)
+		}
+		for _, expected := range []User{{ID: "10", Name: "John"}, {ID: "11", Name: "Jane"}} {
+			if user, err := repo.Get(ContextWithFormat(ctx, "legacy"), expected.ID); err != nil || user != expected {
+				t.Errorf("Got %v, %v but expected %v", user, err, expected)
+			}
+		}
+	})
+	t.Run("Should reject unknown format", func(t *testing.T) {
+		repo := newRepo()
+		if err := repo.Set(ContextWithFormat(ctx, "msgpack"), User{ID: "10"}); !errors.Is(err, ErrUnknownFormat) {
+			t.Errorf("Expected unknown format error but got: %v", err)
+		}
+	})
+	t.Run("Should reject untagged entity", func(t *testing.T) {
+		if _, err := (NegotiatedSerializer[User]{}).UnSerialize([]byte{5, 'j'}); err == nil {
+			t.Error("Expected error of entity without format tag")
+		}
+	})
+}
@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// Normalize canonicalizes identifiers before calling next repository,
+// so variants of an identifier (case, whitespace, ...) map to the same entity.
+type Normalize[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+	// Key returns canonical form of an identifier.
+	Key func(K) K
+	// WithIdentifier returns entity with replaced identifier. Entities with non-canonical identifiers
+	// are rejected with ErrNonCanonicalIdentifier when nil.
+	WithIdentifier func(T, K) T
+}
+
+// ErrNonCanonicalIdentifier is returned by Normalize for entities it can't store under canonical identifier.
+var ErrNonCanonicalIdentifier = errors.New("identifier is not canonical")
+
+func (n Normalize[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return n.Next.Get(ctx, n.Key(id))
+}
+
+func (n Normalize[T, K]) Set(ctx context.Context, entity T) error {
+	if id := n.Key(entity.Identifier()); id != entity.Identifier() {
+		if n.WithIdentifier == nil {
+			return ErrNonCanonicalIdentifier
+		}
+		entity = n.WithIdentifier(entity, id)
+	}
+	return n.Next.Set(ctx, entity)
+}
+
+func (n Normalize[T, K]) Delete(ctx context.Context, id K) error {
+	return n.Next.Delete(ctx, n.Key(id))
+}
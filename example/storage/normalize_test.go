@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	ctx := context.Background()
+	repo := Normalize[User, UserID]{
+		Next: NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}),
+		Key: func(id UserID) UserID {
+			return UserID(strings.ToLower(strings.TrimSpace(string(id))))
+		},
+		WithIdentifier: func(u User, id UserID) User {
+			u.ID = id
+			return u
+		},
+	}
+
+	t.Run("Should store entity under canonical identifier", func(t *testing.T) {
+		_ = repo.Set(ctx, User{ID: " User-10", Name: "John"})
+		for _, id := range []UserID{"user-10", "USER-10 "} {
+			user, err := repo.Get(ctx, id)
+			if err != nil || user.Name != "John" || user.ID != "user-10" {
+				t.Errorf("Got %v, %v for '%s' but expected John", user, err, id)
+			}
+		}
+	})
+	t.Run("Should delete entity by variant identifier", func(t *testing.T) {
+		_ = repo.Delete(ctx, "User-10")
+		if _, err := repo.Get(ctx, "user-10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+	})
+	t.Run("Should reject non-canonical identifier without WithIdentifier", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := Normalize[User, UserID]{Next: next, Key: repo.Key}
+		if err := repo.Set(ctx, User{ID: "User-10"}); !errors.Is(err, ErrNonCanonicalIdentifier) {
+			t.Errorf("Expected non-canonical identifier error but got: %v", err)
+		}
+		if err := repo.Set(ctx, User{ID: "user-10"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if calls := next.count("Set"); calls != 1 {
+			t.Errorf("Got %d downstream Sets but expected 1", calls)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+type (
+	// OKGetter is implemented by repositories reporting missing entities with a flag instead of an error.
+	OKGetter[T Entity[K], K Identifier] interface {
+		GetOK(ctx context.Context, id K) (T, bool, error)
+	}
+
+	// NotFoundAsZero adds GetOK to a repository.
+	NotFoundAsZero[T Entity[K], K Identifier] struct {
+		Next Repository[T, K]
+	}
+)
+
+// GetOK returns zero entity and false for missing entity. Other errors are returned as is.
+func (n NotFoundAsZero[T, K]) GetOK(ctx context.Context, id K) (T, bool, error) {
+	entity, err := n.Next.Get(ctx, id)
+	if errors.Is(err, errNotFound) {
+		var zero T
+		return zero, false, nil
+	}
+	if err != nil {
+		return entity, false, err
+	}
+	return entity, true, nil
+}
+
+func (n NotFoundAsZero[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return n.Next.Get(ctx, id)
+}
+
+func (n NotFoundAsZero[T, K]) Set(ctx context.Context, entity T) error {
+	return n.Next.Set(ctx, entity)
+}
+
+func (n NotFoundAsZero[T, K]) Delete(ctx context.Context, id K) error {
+	return n.Next.Delete(ctx, id)
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestNotFoundAsZero_GetOK(t *testing.T) {
+	ctx := context.Background()
+	storage := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+	_ = storage.Set(ctx, User{ID: "10", Name: "John"})
+	var repo OKGetter[User, UserID] = NotFoundAsZero[User, UserID]{Next: storage}
+
+	t.Run("Should return present entity", func(t *testing.T) {
+		user, ok, err := repo.GetOK(ctx, "10")
+		if err != nil || !ok || user.Name != "John" {
+			t.Errorf("Got %v, %t, %v but expected John", user, ok, err)
+		}
+	})
+	t.Run("Should return zero entity for absent entity", func(t *testing.T) {
+		user, ok, err := repo.GetOK(ctx, "11")
+		if err != nil || ok || user != (User{}) {
+			t.Errorf("Got %v, %t, %v but expected zero entity", user, ok, err)
+		}
+	})
+	t.Run("Should return other errors", func(t *testing.T) {
+		failing := NotFoundAsZero[User, UserID]{Next: &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{}, errExample
+		}}}
+		_, ok, err := failing.GetOK(ctx, "10")
+		if !errors.Is(err, errExample) || ok {
+			t.Errorf("Expected example error but got: %t, %v", ok, err)
+		}
+	})
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"github.com/jlisicki/middlewarebuilder"
+	"time"
+)
+
+type (
+	// ObserveFunc is called after every repository operation with its name, start time and result.
+	ObserveFunc func(op string, start time.Time, err error)
+
+	// Observer reports every repository operation to a single callback.
+	Observer[T Entity[K], K Identifier] struct {
+		Next    Repository[T, K]
+		Observe ObserveFunc
+	}
+)
+
+// ObserveRepo creates a middleware factory reporting Get, Set and Delete calls to observe.
+func ObserveRepo[T Entity[K], K Identifier](observe ObserveFunc) middlewarebuilder.Factory[Repository[T, K]] {
+	return middlewarebuilder.FactoryFunc[Repository[T, K]](func(next Repository[T, K]) (Repository[T, K], error) {
+		return Observer[T, K]{Next: next, Observe: observe}, nil
+	})
+}
+
+func (o Observer[T, K]) Get(ctx context.Context, id K) (entity T, err error) {
+	sT := time.Now()
+	defer func() {
+		o.Observe("Get", sT, err)
+	}()
+	return o.Next.Get(ctx, id)
+}
+
+func (o Observer[T, K]) Set(ctx context.Context, entity T) (err error) {
+	sT := time.Now()
+	defer func() {
+		o.Observe("Set", sT, err)
+	}()
+	return o.Next.Set(ctx, entity)
+}
+
+func (o Observer[T, K]) Delete(ctx context.Context, id K) (err error) {
+	sT := time.Now()
+	defer func() {
+		o.Observe("Delete", sT, err)
+	}()
+	return o.Next.Delete(ctx, id)
+}
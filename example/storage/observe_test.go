@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/jlisicki/middlewarebuilder"
+	"testing"
+	"time"
+)
+
+type observedCall struct {
+	op  string
+	err error
+}
+
+func TestObserveRepo(t *testing.T) {
+	t.Run("Should report every operation with its name and error", func(t *testing.T) {
+		var calls []observedCall
+		repo, err := middlewarebuilder.NewBuilder[UserRepository]().
+			Add(ObserveRepo[User, UserID](func(op string, start time.Time, err error) {
+				if start.IsZero() {
+					t.Errorf("Expected start time for %s", op)
+				}
+				calls = append(calls, observedCall{op: op, err: err})
+			})).
+			WithHandler(NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		ctx := context.Background()
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		_, _ = repo.Get(ctx, "10")
+		_ = repo.Delete(ctx, "10")
+		_, _ = repo.Get(ctx, "10")
+
+		expected := []observedCall{{op: "Set"}, {op: "Get"}, {op: "Delete"}, {op: "Get", err: errNotFound}}
+		if len(calls) != len(expected) {
+			t.Fatalf("Got %d calls but expected %d", len(calls), len(expected))
+		}
+		for i, call := range calls {
+			if call.op != expected[i].op || !errors.Is(call.err, expected[i].err) {
+				t.Errorf("Got call %d %v but expected %v", i, call, expected[i])
+			}
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package storage
+
+import "context"
+
+// Override returns entities set with ContextWithOverrides without calling Next, making scenario tests concise.
+// Writes always reach Next.
+type Override[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+}
+
+// overridesCtxKey is distinct for every entity type, so overrides of different repositories don't replace each other.
+type overridesCtxKey[T any, K Identifier] struct{}
+
+// ContextWithOverrides makes Override return given entities for their identifiers.
+func ContextWithOverrides[T Entity[K], K Identifier](ctx context.Context, overrides map[K]T) context.Context {
+	return context.WithValue(ctx, overridesCtxKey[T, K]{}, overrides)
+}
+
+func (o Override[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if overrides, ok := ctx.Value(overridesCtxKey[T, K]{}).(map[K]T); ok {
+		if entity, overridden := overrides[id]; overridden {
+			return entity, nil
+		}
+	}
+	return o.Next.Get(ctx, id)
+}
+
+func (o Override[T, K]) Set(ctx context.Context, entity T) error {
+	return o.Next.Set(ctx, entity)
+}
+
+func (o Override[T, K]) Delete(ctx context.Context, id K) error {
+	return o.Next.Delete(ctx, id)
+}
@@ -0,0 +1,37 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+func TestOverride_Get(t *testing.T) {
+	next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+		return User{ID: id, Name: "stored"}, nil
+	}}
+	repo := Override[User, UserID]{Next: next}
+	ctx := ContextWithOverrides[User, UserID](context.Background(), map[UserID]User{"10": {ID: "10", Name: "overridden"}})
+
+	t.Run("Should return override without calling next", func(t *testing.T) {
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "overridden" {
+			t.Errorf("Got %v, %v but expected overridden entity", user, err)
+		}
+		if calls := next.count("Get"); calls != 0 {
+			t.Errorf("Got %d downstream Gets but expected none", calls)
+		}
+	})
+	t.Run("Should read other identifiers from next", func(t *testing.T) {
+		if user, err := repo.Get(ctx, "11"); err != nil || user.Name != "stored" {
+			t.Errorf("Got %v, %v but expected stored entity", user, err)
+		}
+		if calls := next.count("Get"); calls != 1 {
+			t.Errorf("Got %d downstream Gets but expected 1", calls)
+		}
+	})
+	t.Run("Should ignore overrides of other entity types", func(t *testing.T) {
+		ctx := ContextWithOverrides[counter, UserID](context.Background(), map[UserID]counter{"12": {ID: "12"}})
+		if user, err := repo.Get(ctx, "12"); err != nil || user.Name != "stored" {
+			t.Errorf("Got %v, %v but expected stored entity", user, err)
+		}
+	})
+}
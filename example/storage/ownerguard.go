@@ -0,0 +1,42 @@
+package storage
+
+import "context"
+
+// OwnerGuard hides entities not owned by principal in context, see ContextWithPrincipal.
+// Entities of other owners are reported as not found, so their existence isn't revealed.
+type OwnerGuard[T Entity[K], K Identifier] struct {
+	Next  Repository[T, K]
+	Owner func(T) string
+}
+
+type adminCtxKey string
+
+var admin adminCtxKey = "admin"
+
+// ContextWithAdmin lets admins read entities of any owner through OwnerGuard.
+func ContextWithAdmin(ctx context.Context) context.Context {
+	return context.WithValue(ctx, admin, "enabled")
+}
+
+func (o OwnerGuard[T, K]) Get(ctx context.Context, id K) (T, error) {
+	entity, err := o.Next.Get(ctx, id)
+	if err != nil {
+		return entity, err
+	}
+	if _, ok := ctx.Value(admin).(string); ok {
+		return entity, nil
+	}
+	if principal, ok := principalFromContext(ctx); !ok || principal != o.Owner(entity) {
+		var zero T
+		return zero, errNotFound
+	}
+	return entity, nil
+}
+
+func (o OwnerGuard[T, K]) Set(ctx context.Context, entity T) error {
+	return o.Next.Set(ctx, entity)
+}
+
+func (o OwnerGuard[T, K]) Delete(ctx context.Context, id K) error {
+	return o.Next.Delete(ctx, id)
+}
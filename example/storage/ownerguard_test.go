@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestOwnerGuard_Get(t *testing.T) {
+	ctx := context.Background()
+	repo := OwnerGuard[User, UserID]{
+		Next: &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id, Name: "alice"}, nil
+		}},
+		Owner: func(u User) string {
+			return u.Name
+		},
+	}
+
+	t.Run("Should return entity of owner", func(t *testing.T) {
+		if user, err := repo.Get(ContextWithPrincipal(ctx, "alice"), "10"); err != nil || user.ID != "10" {
+			t.Errorf("Got %v, %v but expected entity", user, err)
+		}
+	})
+	t.Run("Should report entity of other owner as not found", func(t *testing.T) {
+		user, err := repo.Get(ContextWithPrincipal(ctx, "bob"), "10")
+		if !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+		if user != (User{}) {
+			t.Errorf("Got %v but expected no entity", user)
+		}
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error without principal but got: %v", err)
+		}
+	})
+	t.Run("Should return entity of any owner to admin", func(t *testing.T) {
+		if user, err := repo.Get(ContextWithAdmin(ContextWithPrincipal(ctx, "bob")), "10"); err != nil || user.ID != "10" {
+			t.Errorf("Got %v, %v but expected entity", user, err)
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+type (
+	// RateLimitConfig configures a token bucket refilled with Rate tokens per second up to Burst tokens.
+	RateLimitConfig struct {
+		Rate  float64
+		Burst int
+		// MaxKeys bounds number of tracked limiters; the least recently used one is evicted above it.
+		MaxKeys int
+	}
+
+	// PerKeyRateLimit gives every entity identifier its own rate limit budget,
+	// so a hot key can't starve the others.
+	PerKeyRateLimit[T Entity[K], K Identifier] struct {
+		Next                 Repository[T, K]
+		identifierSerializer serializer[K]
+		limiters             *keyedLimiters
+	}
+
+	// TenantRateLimit gives every tenant read from context its own rate limit budget,
+	// so a busy tenant can't starve the others. Operations without tenant share DefaultTenant budget.
+	TenantRateLimit[T Entity[K], K Identifier] struct {
+		Next     Repository[T, K]
+		limiters *keyedLimiters
+	}
+
+	// OpRateLimitConfig configures limits of every operation separately. Nil limit means unlimited.
+	OpRateLimitConfig struct {
+		Get    *RateLimitConfig
+		Set    *RateLimitConfig
+		Delete *RateLimitConfig
+	}
+
+	// OpRateLimit limits rate of every operation with its own token bucket,
+	// e.g. to throttle writes hard while leaving reads fast.
+	OpRateLimit[T Entity[K], K Identifier] struct {
+		Next    Repository[T, K]
+		now     func() time.Time
+		lock    sync.Mutex
+		buckets map[string]*tokenBucket
+	}
+
+	tokenBucket struct {
+		rate   float64
+		burst  float64
+		tokens float64
+		last   time.Time
+	}
+
+	keyedLimiters struct {
+		config  RateLimitConfig
+		now     func() time.Time
+		lock    sync.Mutex
+		buckets map[string]*list.Element
+		lru     *list.List
+	}
+	keyedBucket struct {
+		key    string
+		bucket *tokenBucket
+	}
+)
+
+// ErrRateLimited is returned for operations exceeding their rate limit.
+var ErrRateLimited = errors.New("rate limit exceeded")
+
+// DefaultTenant is budget of operations without tenant in context.
+const DefaultTenant = "default"
+
+func NewPerKeyRateLimit[T Entity[K], K Identifier](next Repository[T, K], identitySerializer serializer[K], config RateLimitConfig) *PerKeyRateLimit[T, K] {
+	return &PerKeyRateLimit[T, K]{
+		Next:                 next,
+		identifierSerializer: identitySerializer,
+		limiters:             newKeyedLimiters(config, time.Now),
+	}
+}
+
+func (p *PerKeyRateLimit[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if err := p.allow(id); err != nil {
+		var entity T
+		return entity, err
+	}
+	return p.Next.Get(ctx, id)
+}
+
+func (p *PerKeyRateLimit[T, K]) Set(ctx context.Context, entity T) error {
+	if err := p.allow(entity.Identifier()); err != nil {
+		return err
+	}
+	return p.Next.Set(ctx, entity)
+}
+
+func (p *PerKeyRateLimit[T, K]) Delete(ctx context.Context, id K) error {
+	if err := p.allow(id); err != nil {
+		return err
+	}
+	return p.Next.Delete(ctx, id)
+}
+
+func (p *PerKeyRateLimit[T, K]) allow(id K) error {
+	key, err := p.identifierSerializer.Serialize(id)
+	if err != nil {
+		return fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	if !p.limiters.allow(string(key)) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+func NewOpRateLimit[T Entity[K], K Identifier](next Repository[T, K], config OpRateLimitConfig) *OpRateLimit[T, K] {
+	r := &OpRateLimit[T, K]{Next: next, now: time.Now, buckets: make(map[string]*tokenBucket)}
+	now := r.now()
+	for op, limit := range map[string]*RateLimitConfig{"Get": config.Get, "Set": config.Set, "Delete": config.Delete} {
+		if limit != nil {
+			r.buckets[op] = newTokenBucket(limit.Rate, limit.Burst, now)
+		}
+	}
+	return r
+}
+
+func (r *OpRateLimit[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if !r.allow("Get") {
+		var entity T
+		return entity, ErrRateLimited
+	}
+	return r.Next.Get(ctx, id)
+}
+
+func (r *OpRateLimit[T, K]) Set(ctx context.Context, entity T) error {
+	if !r.allow("Set") {
+		return ErrRateLimited
+	}
+	return r.Next.Set(ctx, entity)
+}
+
+func (r *OpRateLimit[T, K]) Delete(ctx context.Context, id K) error {
+	if !r.allow("Delete") {
+		return ErrRateLimited
+	}
+	return r.Next.Delete(ctx, id)
+}
+
+func (r *OpRateLimit[T, K]) allow(op string) bool {
+	bucket, limited := r.buckets[op]
+	if !limited {
+		return true
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return bucket.allow(r.now())
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: now}
+}
+
+// allow takes a token from the bucket if one is available.
+func (b *tokenBucket) allow(now time.Time) bool {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens += elapsed.Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+		b.last = now
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func NewTenantRateLimit[T Entity[K], K Identifier](next Repository[T, K], config RateLimitConfig) *TenantRateLimit[T, K] {
+	return &TenantRateLimit[T, K]{
+		Next:     next,
+		limiters: newKeyedLimiters(config, time.Now),
+	}
+}
+
+func (t *TenantRateLimit[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if err := t.allow(ctx); err != nil {
+		var entity T
+		return entity, err
+	}
+	return t.Next.Get(ctx, id)
+}
+
+func (t *TenantRateLimit[T, K]) Set(ctx context.Context, entity T) error {
+	if err := t.allow(ctx); err != nil {
+		return err
+	}
+	return t.Next.Set(ctx, entity)
+}
+
+func (t *TenantRateLimit[T, K]) Delete(ctx context.Context, id K) error {
+	if err := t.allow(ctx); err != nil {
+		return err
+	}
+	return t.Next.Delete(ctx, id)
+}
+
+func (t *TenantRateLimit[T, K]) allow(ctx context.Context) error {
+	tenant, ok := tenantFromContext(ctx)
+	if !ok {
+		tenant = DefaultTenant
+	}
+	if !t.limiters.allow(tenant) {
+		return ErrRateLimited
+	}
+	return nil
+}
+
+func newKeyedLimiters(config RateLimitConfig, now func() time.Time) *keyedLimiters {
+	return &keyedLimiters{
+		config:  config,
+		now:     now,
+		buckets: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+func (k *keyedLimiters) allow(key string) bool {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	now := k.now()
+	element, exists := k.buckets[key]
+	if exists {
+		k.lru.MoveToFront(element)
+	} else {
+		element = k.lru.PushFront(keyedBucket{key: key, bucket: newTokenBucket(k.config.Rate, k.config.Burst, now)})
+		k.buckets[key] = element
+		if k.config.MaxKeys > 0 && k.lru.Len() > k.config.MaxKeys {
+			oldest := k.lru.Back()
+			k.lru.Remove(oldest)
+			delete(k.buckets, oldest.Value.(keyedBucket).key)
+		}
+	}
+	return element.Value.(keyedBucket).bucket.allow(now)
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPerKeyRateLimit(t *testing.T) {
+	ctx := context.Background()
+	newRateLimit := func(now *time.Time) *PerKeyRateLimit[User, UserID] {
+		repo := NewPerKeyRateLimit[User, UserID](&stubRepository{}, userIDSerializer{}, RateLimitConfig{Rate: 1, Burst: 2, MaxKeys: 2})
+		repo.limiters.now = func() time.Time {
+			return *now
+		}
+		return repo
+	}
+
+	t.Run("Should throttle key independently of others", func(t *testing.T) {
+		now := time.Now()
+		repo := newRateLimit(&now)
+		_ = repo.Set(ctx, User{ID: "10"})
+		_ = repo.Delete(ctx, "10")
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected rate limit error but got: %v", err)
+		}
+		if _, err := repo.Get(ctx, "11"); errors.Is(err, ErrRateLimited) {
+			t.Errorf("Unexpected rate limit of another key")
+		}
+	})
+	t.Run("Should refill budget over time", func(t *testing.T) {
+		now := time.Now()
+		repo := newRateLimit(&now)
+		_ = repo.Delete(ctx, "10")
+		_ = repo.Delete(ctx, "10")
+		now = now.Add(time.Second)
+		if err := repo.Delete(ctx, "10"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if err := repo.Delete(ctx, "10"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected rate limit error but got: %v", err)
+		}
+	})
+	t.Run("Should evict least recently used limiter", func(t *testing.T) {
+		now := time.Now()
+		repo := newRateLimit(&now)
+		_ = repo.Delete(ctx, "10")
+		_ = repo.Delete(ctx, "10")
+		_ = repo.Delete(ctx, "11")
+		_ = repo.Delete(ctx, "12")
+		if len(repo.limiters.buckets) != 2 {
+			t.Errorf("Got %d limiters but expected 2", len(repo.limiters.buckets))
+		}
+		if err := repo.Delete(ctx, "10"); err != nil {
+			t.Errorf("Expected fresh budget for evicted key but got: %v", err)
+		}
+	})
+}
+
+func TestOpRateLimit(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should throttle operations independently", func(t *testing.T) {
+		repo := NewOpRateLimit[User, UserID](&stubRepository{}, OpRateLimitConfig{
+			Get: &RateLimitConfig{Rate: 1, Burst: 3},
+			Set: &RateLimitConfig{Rate: 1, Burst: 1},
+		})
+		now := time.Now()
+		repo.now = func() time.Time {
+			return now
+		}
+		if err := repo.Set(ctx, User{ID: "10"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if err := repo.Set(ctx, User{ID: "10"}); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected rate limit error but got: %v", err)
+		}
+		for i := 0; i < 3; i++ {
+			if _, err := repo.Get(ctx, "10"); errors.Is(err, ErrRateLimited) {
+				t.Errorf("Unexpected rate limit of Get %d", i)
+			}
+		}
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected rate limit error but got: %v", err)
+		}
+	})
+	t.Run("Should not limit operations without configuration", func(t *testing.T) {
+		repo := NewOpRateLimit[User, UserID](&stubRepository{}, OpRateLimitConfig{Set: &RateLimitConfig{}})
+		for i := 0; i < 100; i++ {
+			if err := repo.Delete(ctx, "10"); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+		}
+	})
+}
+
+func TestTenantRateLimit(t *testing.T) {
+	ctx := context.Background()
+	newRateLimit := func(now *time.Time) *TenantRateLimit[User, UserID] {
+		repo := NewTenantRateLimit[User, UserID](&stubRepository{}, RateLimitConfig{Rate: 1, Burst: 2, MaxKeys: 2})
+		repo.limiters.now = func() time.Time {
+			return *now
+		}
+		return repo
+	}
+
+	t.Run("Should throttle tenant independently of others", func(t *testing.T) {
+		now := time.Now()
+		repo := newRateLimit(&now)
+		acme, globex := ContextWithTenant(ctx, "acme"), ContextWithTenant(ctx, "globex")
+		_ = repo.Set(acme, User{ID: "10"})
+		_ = repo.Delete(acme, "11")
+		if _, err := repo.Get(acme, "12"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected rate limit error but got: %v", err)
+		}
+		if _, err := repo.Get(globex, "10"); errors.Is(err, ErrRateLimited) {
+			t.Errorf("Unexpected rate limit of another tenant")
+		}
+	})
+	t.Run("Should share default budget without tenant", func(t *testing.T) {
+		now := time.Now()
+		repo := newRateLimit(&now)
+		_ = repo.Delete(ctx, "10")
+		_ = repo.Delete(ContextWithTenant(ctx, DefaultTenant), "11")
+		if err := repo.Delete(ctx, "12"); !errors.Is(err, ErrRateLimited) {
+			t.Errorf("Expected rate limit error but got: %v", err)
+		}
+	})
+	t.Run("Should evict least recently used tenant", func(t *testing.T) {
+		now := time.Now()
+		repo := newRateLimit(&now)
+		acme := ContextWithTenant(ctx, "acme")
+		_ = repo.Delete(acme, "10")
+		_ = repo.Delete(acme, "10")
+		_ = repo.Delete(ContextWithTenant(ctx, "globex"), "10")
+		_ = repo.Delete(ContextWithTenant(ctx, "initech"), "10")
+		if err := repo.Delete(acme, "10"); err != nil {
+			t.Errorf("Expected fresh budget of evicted tenant but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// ReadOnly rejects writes while letting reads through.
+type ReadOnly[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+}
+
+type readWriteOverrideCtxKey string
+
+var readWriteOverride readWriteOverrideCtxKey = "readWriteOverride"
+
+// ErrReadOnly is returned for writes rejected by ReadOnly.
+var ErrReadOnly = errors.New("repository is read-only")
+
+// ContextWithReadWriteOverride allows privileged writes through ReadOnly.
+func ContextWithReadWriteOverride(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readWriteOverride, "enabled")
+}
+
+func (r ReadOnly[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return r.Next.Get(ctx, id)
+}
+
+func (r ReadOnly[T, K]) Set(ctx context.Context, entity T) error {
+	if _, ok := ctx.Value(readWriteOverride).(string); !ok {
+		return ErrReadOnly
+	}
+	return r.Next.Set(ctx, entity)
+}
+
+func (r ReadOnly[T, K]) Delete(ctx context.Context, id K) error {
+	if _, ok := ctx.Value(readWriteOverride).(string); !ok {
+		return ErrReadOnly
+	}
+	return r.Next.Delete(ctx, id)
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestReadOnly(t *testing.T) {
+	storage := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+	_ = storage.Set(context.Background(), User{ID: "10", Name: "John"})
+	repo := ReadOnly[User, UserID]{Next: storage}
+
+	t.Run("Should reject writes", func(t *testing.T) {
+		if err := repo.Set(context.Background(), User{ID: "11"}); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected read-only error on Set but got: %v", err)
+		}
+		if err := repo.Delete(context.Background(), "10"); !errors.Is(err, ErrReadOnly) {
+			t.Errorf("Expected read-only error on Delete but got: %v", err)
+		}
+	})
+	t.Run("Should pass reads through", func(t *testing.T) {
+		user, err := repo.Get(context.Background(), "10")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user.Name != "John" {
+			t.Errorf("Got '%s' but expected 'John'", user.Name)
+		}
+	})
+	t.Run("Should allow writes with override", func(t *testing.T) {
+		ctx := ContextWithReadWriteOverride(context.Background())
+		if err := repo.Set(ctx, User{ID: "11"}); err != nil {
+			t.Errorf("Unexpected error on Set: %s", err)
+		}
+		if err := repo.Delete(ctx, "10"); err != nil {
+			t.Errorf("Unexpected error on Delete: %s", err)
+		}
+		if _, err := storage.Get(ctx, "11"); err != nil {
+			t.Errorf("Expected entity written with override but got: %v", err)
+		}
+	})
+}
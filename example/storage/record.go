@@ -0,0 +1,189 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+type (
+	// Record writes every completed operation to Output, each framed like in Export, so it can be re-applied with Replay.
+	// Failed writes aren't recorded since they didn't change the repository. Gets are recorded with their result.
+	Record[T Entity[K], K Identifier] struct {
+		Next                 Repository[T, K]
+		Output               io.Writer
+		identifierSerializer serializer[K]
+		entitySerializer     serializer[T]
+		lock                 sync.Mutex
+		err                  error
+		now                  func() time.Time
+	}
+
+	recordedOp struct {
+		Op       string
+		Time     time.Time
+		Key      []byte `json:",omitempty"`
+		Entity   []byte `json:",omitempty"`
+		NotFound bool   `json:",omitempty"`
+	}
+
+	replayer[T Entity[K], K Identifier] struct {
+		repo                 Repository[T, K]
+		identifierSerializer serializer[K]
+		entitySerializer     serializer[T]
+		verifyGets           bool
+	}
+)
+
+// ErrReplayMismatch is returned by Replay when a verified Get returns other result than recorded.
+var ErrReplayMismatch = errors.New("replayed result differs from recorded")
+
+func NewRecord[T Entity[K], K Identifier](next Repository[T, K], output io.Writer, identifierSerializer serializer[K], entitySerializer serializer[T]) *Record[T, K] {
+	return &Record[T, K]{
+		Next:                 next,
+		Output:               output,
+		identifierSerializer: identifierSerializer,
+		entitySerializer:     entitySerializer,
+		now:                  time.Now,
+	}
+}
+
+func (r *Record[T, K]) Get(ctx context.Context, id K) (T, error) {
+	entity, err := r.Next.Get(ctx, id)
+	switch {
+	case err == nil:
+		r.record("Get", &id, &entity, false)
+	case errors.Is(err, errNotFound):
+		r.record("Get", &id, nil, true)
+	}
+	return entity, err
+}
+
+func (r *Record[T, K]) Set(ctx context.Context, entity T) error {
+	if err := r.Next.Set(ctx, entity); err != nil {
+		return err
+	}
+	r.record("Set", nil, &entity, false)
+	return nil
+}
+
+func (r *Record[T, K]) Delete(ctx context.Context, id K) error {
+	if err := r.Next.Delete(ctx, id); err != nil {
+		return err
+	}
+	r.record("Delete", &id, nil, false)
+	return nil
+}
+
+// Err returns the first error of writing to Output. Operations are recorded on a best-effort basis and never fail because of it.
+func (r *Record[T, K]) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+// Replay applies operations recorded by Record to repo in recorded order, using serializers the Record was created with.
+// Gets are skipped unless verifyGets is set, then a differing result fails with ErrReplayMismatch.
+func Replay[T Entity[K], K Identifier](ctx context.Context, r io.Reader, repo Repository[T, K], identifierSerializer serializer[K], entitySerializer serializer[T], verifyGets bool) error {
+	replayer := replayer[T, K]{repo: repo, identifierSerializer: identifierSerializer, entitySerializer: entitySerializer, verifyGets: verifyGets}
+	for n := 0; ; n++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		raw, err := readFrame(r)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read operation %d: %w", n, err)
+		}
+		var op recordedOp
+		if err := json.Unmarshal(raw, &op); err != nil {
+			return fmt.Errorf("%w operation %d: %w", ErrDeserialize, n, err)
+		}
+		if err := replayer.replay(ctx, op); err != nil {
+			return fmt.Errorf("unable to replay %s %d: %w", op.Op, n, err)
+		}
+	}
+}
+
+func (r replayer[T, K]) replay(ctx context.Context, op recordedOp) error {
+	switch op.Op {
+	case "Get":
+		if !r.verifyGets {
+			return nil
+		}
+		id, err := r.identifierSerializer.UnSerialize(op.Key)
+		if err != nil {
+			return fmt.Errorf("%w identifier: %w", ErrDeserialize, err)
+		}
+		entity, err := r.repo.Get(ctx, id)
+		if errors.Is(err, errNotFound) {
+			if op.NotFound {
+				return nil
+			}
+			return fmt.Errorf("%w: entity not found", ErrReplayMismatch)
+		}
+		if err != nil {
+			return err
+		}
+		if op.NotFound {
+			return fmt.Errorf("%w: entity found", ErrReplayMismatch)
+		}
+		raw, err := r.entitySerializer.Serialize(entity)
+		if err != nil {
+			return fmt.Errorf("%w entity: %w", ErrSerialize, err)
+		}
+		if !bytes.Equal(raw, op.Entity) {
+			return fmt.Errorf("%w: entity differs", ErrReplayMismatch)
+		}
+		return nil
+	case "Set":
+		entity, err := r.entitySerializer.UnSerialize(op.Entity)
+		if err != nil {
+			return fmt.Errorf("%w entity: %w", ErrDeserialize, err)
+		}
+		return r.repo.Set(ctx, entity)
+	case "Delete":
+		id, err := r.identifierSerializer.UnSerialize(op.Key)
+		if err != nil {
+			return fmt.Errorf("%w identifier: %w", ErrDeserialize, err)
+		}
+		return r.repo.Delete(ctx, id)
+	default:
+		return fmt.Errorf("%w: unknown operation", ErrDeserialize)
+	}
+}
+
+func (r *Record[T, K]) record(op string, id *K, entity *T, notFound bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if r.err != nil {
+		return
+	}
+	r.err = r.write(recordedOp{Op: op, Time: r.now(), NotFound: notFound}, id, entity)
+}
+
+func (r *Record[T, K]) write(op recordedOp, id *K, entity *T) error {
+	var err error
+	if id != nil {
+		if op.Key, err = r.identifierSerializer.Serialize(*id); err != nil {
+			return fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+		}
+	}
+	if entity != nil {
+		if op.Entity, err = r.entitySerializer.Serialize(*entity); err != nil {
+			return fmt.Errorf("%w entity: %w", ErrSerialize, err)
+		}
+	}
+	raw, err := json.Marshal(op)
+	if err != nil {
+		return fmt.Errorf("%w operation: %w", ErrSerialize, err)
+	}
+	return writeFrame(r.Output, raw)
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRecord_Replay(t *testing.T) {
+	ctx := context.Background()
+	recordSequence := func() (*Record[User, UserID], *bytes.Buffer) {
+		var log bytes.Buffer
+		repo := NewRecord[User, UserID](NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}), &log, userIDSerializer{}, userSerializer{})
+		_ = repo.Set(ctx, User{ID: "10", Name: "first"})
+		_ = repo.Set(ctx, User{ID: "11", Name: "second"})
+		_, _ = repo.Get(ctx, "10")
+		_ = repo.Set(ctx, User{ID: "10", Name: "renamed"})
+		_ = repo.Delete(ctx, "11")
+		_, _ = repo.Get(ctx, "11")
+		return repo, &log
+	}
+
+	t.Run("Should rebuild repository state", func(t *testing.T) {
+		rec, log := recordSequence()
+		if err := rec.Err(); err != nil {
+			t.Fatalf("Unexpected recording error: %s", err)
+		}
+		fresh := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		if err := Replay[User, UserID](ctx, log, fresh, userIDSerializer{}, userSerializer{}, true); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user, err := fresh.Get(ctx, "10"); err != nil || user.Name != "renamed" {
+			t.Errorf("Got %v, %v but expected renamed entity", user, err)
+		}
+		if _, err := fresh.Get(ctx, "11"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+	})
+	t.Run("Should report Gets differing from recorded", func(t *testing.T) {
+		_, log := recordSequence()
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id, Name: "other"}, nil
+		}}
+		if err := Replay[User, UserID](ctx, log, next, userIDSerializer{}, userSerializer{}, true); !errors.Is(err, ErrReplayMismatch) {
+			t.Errorf("Expected replay mismatch error but got: %v", err)
+		}
+	})
+	t.Run("Should skip Gets unless verified", func(t *testing.T) {
+		_, log := recordSequence()
+		next := &stubRepository{}
+		if err := Replay[User, UserID](ctx, log, next, userIDSerializer{}, userSerializer{}, false); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if calls := next.count("Get"); calls != 0 {
+			t.Errorf("Got %d downstream Gets but expected none", calls)
+		}
+		if calls := next.count("Set"); calls != 3 {
+			t.Errorf("Got %d downstream Sets but expected 3", calls)
+		}
+	})
+	t.Run("Should not record failed writes", func(t *testing.T) {
+		var log bytes.Buffer
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			return errExample
+		}}
+		rec := NewRecord[User, UserID](next, &log, userIDSerializer{}, userSerializer{})
+		_ = rec.Set(ctx, User{ID: "10"})
+		if log.Len() != 0 {
+			t.Errorf("Got %d recorded bytes but expected none", log.Len())
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// TraceEntry describes a single repository operation.
+	TraceEntry[K Identifier] struct {
+		Op       string
+		Key      K
+		Err      error
+		Duration time.Duration
+		Time     time.Time
+	}
+
+	// Recorder keeps the last operations in a bounded ring buffer for post-mortem debugging.
+	// Entries are recorded after downstream call returns, so recording never blocks it.
+	Recorder[T Entity[K], K Identifier] struct {
+		Next    Repository[T, K]
+		lock    sync.Mutex
+		entries []TraceEntry[K]
+		next    int
+		full    bool
+	}
+)
+
+func NewRecorder[T Entity[K], K Identifier](next Repository[T, K], capacity int) *Recorder[T, K] {
+	return &Recorder[T, K]{
+		Next:    next,
+		entries: make([]TraceEntry[K], capacity),
+	}
+}
+
+func (r *Recorder[T, K]) Get(ctx context.Context, id K) (entity T, err error) {
+	sT := time.Now()
+	defer func() {
+		r.record("Get", id, err, sT)
+	}()
+	return r.Next.Get(ctx, id)
+}
+
+func (r *Recorder[T, K]) Set(ctx context.Context, entity T) (err error) {
+	sT := time.Now()
+	defer func() {
+		r.record("Set", entity.Identifier(), err, sT)
+	}()
+	return r.Next.Set(ctx, entity)
+}
+
+func (r *Recorder[T, K]) Delete(ctx context.Context, id K) (err error) {
+	sT := time.Now()
+	defer func() {
+		r.record("Delete", id, err, sT)
+	}()
+	return r.Next.Delete(ctx, id)
+}
+
+// Dump returns recorded entries from the oldest to the newest.
+func (r *Recorder[T, K]) Dump() []TraceEntry[K] {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if !r.full {
+		return append([]TraceEntry[K](nil), r.entries[:r.next]...)
+	}
+	return append(append([]TraceEntry[K](nil), r.entries[r.next:]...), r.entries[:r.next]...)
+}
+
+func (r *Recorder[T, K]) record(op string, id K, err error, sT time.Time) {
+	entry := TraceEntry[K]{Op: op, Key: id, Err: err, Duration: time.Since(sT), Time: sT}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	if len(r.entries) == 0 {
+		return
+	}
+	r.entries[r.next] = entry
+	r.next = (r.next + 1) % len(r.entries)
+	if r.next == 0 {
+		r.full = true
+	}
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestRecorder_Dump(t *testing.T) {
+	t.Run("Should keep entries in order", func(t *testing.T) {
+		repo := NewRecorder[User, UserID](NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}), 3)
+		ctx := context.Background()
+		_ = repo.Set(ctx, User{ID: "10"})
+		_, _ = repo.Get(ctx, "11")
+
+		entries := repo.Dump()
+		if len(entries) != 2 {
+			t.Fatalf("Got %d entries but expected 2", len(entries))
+		}
+		if entries[0].Op != "Set" || entries[0].Key != "10" || entries[0].Err != nil {
+			t.Errorf("Unexpected first entry: %v", entries[0])
+		}
+		if entries[1].Op != "Get" || entries[1].Key != "11" || !errors.Is(entries[1].Err, errNotFound) {
+			t.Errorf("Unexpected second entry: %v", entries[1])
+		}
+	})
+	t.Run("Should drop oldest entries over capacity", func(t *testing.T) {
+		repo := NewRecorder[User, UserID](NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}), 3)
+		ctx := context.Background()
+		for _, id := range []UserID{"1", "2", "3", "4", "5"} {
+			_ = repo.Delete(ctx, id)
+		}
+
+		entries := repo.Dump()
+		expected := []UserID{"3", "4", "5"}
+		if len(entries) != len(expected) {
+			t.Fatalf("Got %d entries but expected %d", len(entries), len(expected))
+		}
+		for i, entry := range entries {
+			if entry.Key != expected[i] {
+				t.Errorf("Got key '%s' at %d but expected '%s'", entry.Key, i, expected[i])
+			}
+		}
+	})
+	t.Run("Should record concurrent operations", func(t *testing.T) {
+		repo := NewRecorder[User, UserID](NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}), 5)
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _ = repo.Get(context.Background(), "10")
+				_ = repo.Dump()
+			}()
+		}
+		wg.Wait()
+		if entries := repo.Dump(); len(entries) != 5 {
+			t.Errorf("Got %d entries but expected 5", len(entries))
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// RepoRecover converts panics from downstream repository into errors.
+type RepoRecover[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+	// RepanicOnRuntimeError keeps crashing on runtime errors (nil dereference, out of range, ...)
+	// for those who prefer programmer bugs to surface as panics.
+	RepanicOnRuntimeError bool
+}
+
+var errRecoveredPanic = errors.New("recovered panic")
+
+func (r RepoRecover[T, K]) Get(ctx context.Context, id K) (entity T, err error) {
+	defer r.recover("Get", &err)
+	return r.Next.Get(ctx, id)
+}
+
+func (r RepoRecover[T, K]) Set(ctx context.Context, entity T) (err error) {
+	defer r.recover("Set", &err)
+	return r.Next.Set(ctx, entity)
+}
+
+func (r RepoRecover[T, K]) Delete(ctx context.Context, id K) (err error) {
+	defer r.recover("Delete", &err)
+	return r.Next.Delete(ctx, id)
+}
+
+func (r RepoRecover[T, K]) recover(op string, err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	if _, isRuntimeError := recovered.(runtime.Error); isRuntimeError && r.RepanicOnRuntimeError {
+		panic(recovered)
+	}
+	*err = fmt.Errorf("%w in %s: %v", errRecoveredPanic, op, recovered)
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+type panickingUserSerializer struct {
+	fail func()
+}
+
+func (p panickingUserSerializer) Serialize(u User) ([]byte, error) {
+	p.fail()
+	return nil, nil
+}
+
+func (p panickingUserSerializer) UnSerialize(bytes []byte) (User, error) {
+	p.fail()
+	return User{}, nil
+}
+
+func TestRepoRecover(t *testing.T) {
+	t.Run("Should convert panic to error with operation name", func(t *testing.T) {
+		repo := RepoRecover[User, UserID]{
+			Next: NewInMemoryRepository[User, UserID](userIDSerializer{}, panickingUserSerializer{fail: func() {
+				panic("broken serializer")
+			}}),
+		}
+		err := repo.Set(context.Background(), User{ID: "10"})
+		if !errors.Is(err, errRecoveredPanic) {
+			t.Fatalf("Expected recovered panic error but got: %v", err)
+		}
+		if !strings.Contains(err.Error(), "Set") || !strings.Contains(err.Error(), "broken serializer") {
+			t.Errorf("Expected operation name and panic value in error but got: %s", err)
+		}
+	})
+	t.Run("Should pass through results without panic", func(t *testing.T) {
+		repo := RepoRecover[User, UserID]{Next: NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})}
+		_, err := repo.Get(context.Background(), "10")
+		if !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+	})
+	t.Run("Should re-panic on runtime error when configured", func(t *testing.T) {
+		repo := RepoRecover[User, UserID]{
+			Next: NewInMemoryRepository[User, UserID](userIDSerializer{}, panickingUserSerializer{fail: func() {
+				var entities map[UserID]User
+				entities["10"] = User{}
+			}}),
+			RepanicOnRuntimeError: true,
+		}
+		defer func() {
+			if _, isRuntimeError := recover().(runtime.Error); !isRuntimeError {
+				t.Error("Expected runtime error panic")
+			}
+		}()
+		_ = repo.Set(context.Background(), User{ID: "10"})
+	})
+}
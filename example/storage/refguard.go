@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// RefGuard protects entities referenced by others from being deleted.
+type RefGuard[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+	// HasDependents reports whether other entities reference the entity.
+	HasDependents func(ctx context.Context, id K) (bool, error)
+	// Cascade deletes dependents before the entity. Deletes with dependents are rejected when nil.
+	Cascade func(ctx context.Context, id K) error
+}
+
+// ErrHasDependents is returned by RefGuard for deletes of entities referenced by others.
+var ErrHasDependents = errors.New("entity has dependents")
+
+func (r RefGuard[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return r.Next.Get(ctx, id)
+}
+
+func (r RefGuard[T, K]) Set(ctx context.Context, entity T) error {
+	return r.Next.Set(ctx, entity)
+}
+
+func (r RefGuard[T, K]) Delete(ctx context.Context, id K) error {
+	hasDependents, err := r.HasDependents(ctx, id)
+	if err != nil {
+		return err
+	}
+	if hasDependents {
+		if r.Cascade == nil {
+			return ErrHasDependents
+		}
+		if err := r.Cascade(ctx, id); err != nil {
+			return err
+		}
+	}
+	return r.Next.Delete(ctx, id)
+}
@@ -0,0 +1,56 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRefGuard_Delete(t *testing.T) {
+	ctx := context.Background()
+	dependents := map[UserID][]UserID{"10": {"20", "21"}}
+	hasDependents := func(ctx context.Context, id UserID) (bool, error) {
+		return len(dependents[id]) > 0, nil
+	}
+
+	t.Run("Should block delete of entity with dependents", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := RefGuard[User, UserID]{Next: next, HasDependents: hasDependents}
+		if err := repo.Delete(ctx, "10"); !errors.Is(err, ErrHasDependents) {
+			t.Errorf("Expected has dependents error but got: %v", err)
+		}
+		if err := repo.Delete(ctx, "11"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if calls := next.count("Delete"); calls != 1 {
+			t.Errorf("Got %d downstream Deletes but expected 1", calls)
+		}
+	})
+	t.Run("Should delete dependents first with cascade", func(t *testing.T) {
+		var deleted []UserID
+		next := &stubRepository{deleteFunc: func(ctx context.Context, id UserID) error {
+			deleted = append(deleted, id)
+			return nil
+		}}
+		repo := RefGuard[User, UserID]{
+			Next:          next,
+			HasDependents: hasDependents,
+			Cascade: func(ctx context.Context, id UserID) error {
+				deleted = append(deleted, dependents[id]...)
+				return nil
+			},
+		}
+		if err := repo.Delete(ctx, "10"); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		expected := []UserID{"20", "21", "10"}
+		if len(deleted) != len(expected) {
+			t.Fatalf("Got %v but expected %v", deleted, expected)
+		}
+		for i, id := range deleted {
+			if id != expected[i] {
+				t.Errorf("Got %v but expected %v", deleted, expected)
+			}
+		}
+	})
+}
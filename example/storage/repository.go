@@ -1,12 +1,17 @@
 package storage
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
 	"log"
+	"maps"
+	"math"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,6 +28,37 @@ type (
 		Delete(ctx context.Context, id K) error
 	}
 
+	// GetOrCreator is implemented by repositories able to atomically get an entity or create it when missing.
+	GetOrCreator[T Entity[K], K Identifier] interface {
+		GetOrCreate(ctx context.Context, id K, create func() T) (T, error)
+	}
+
+	// Patcher is implemented by repositories able to atomically read, modify and write back an entity.
+	Patcher[T Entity[K], K Identifier] interface {
+		Patch(ctx context.Context, id K, mutate func(T) (T, error)) error
+	}
+
+	// CompareAndSetter is implemented by repositories able to replace an entity only if it's unchanged.
+	CompareAndSetter[T any] interface {
+		CompareAndSet(ctx context.Context, expected T, new T) (bool, error)
+	}
+
+	// Transactional is implemented by repositories able to apply a group of operations atomically.
+	// Operations made through the repository passed to fn are rolled back when fn returns an error.
+	Transactional[T Entity[K], K Identifier] interface {
+		InTx(ctx context.Context, fn func(Repository[T, K]) error) error
+	}
+
+	// SelfTester is implemented by repositories able to verify they work with a round-trip of a sentinel entity.
+	SelfTester interface {
+		SelfTest(ctx context.Context) error
+	}
+
+	// Counter is implemented by repositories able to count stored entities.
+	Counter interface {
+		Count(ctx context.Context) (int, error)
+	}
+
 	serializer[T any] interface {
 		Serialize(T) ([]byte, error)
 		UnSerialize([]byte) (T, error)
@@ -36,18 +72,58 @@ type (
 	}
 	// Cache for repository in local memory.
 	Cache[T Entity[K], K Identifier] struct {
-		Next   Repository[T, K]
-		cached map[K]T
-		lock   sync.Mutex
+		Next Repository[T, K]
+		// Cacheable reports whether entity may be cached. All entities are cached when nil.
+		Cacheable func(T) bool
+		// WarmingBypass reports whether identifier not yet cached may be fetched from Next during Warm.
+		WarmingBypass func(K) bool
+		// Intern serializes entities so that identifiers resolving to equal entities share a single copy.
+		// Entities aren't interned when nil.
+		Intern serializer[T]
+		// Serializer of entities written by Persist and read by LoadPersisted.
+		Serializer serializer[T]
+		// PersistOnClose receives cached entities on Close when set.
+		PersistOnClose io.Writer
+		// Logger receives warnings of LoadPersisted. Defaults to the standard logger.
+		Logger *log.Logger
+		// MaxEntries bounds number of cached entities, evicting an arbitrary one to make room. Unbounded when 0.
+		MaxEntries   int
+		cached       map[K]T
+		interned     map[string]*internedEntity[T]
+		internedKeys map[K]string
+		lock         sync.Mutex
+		warming      bool
+	}
+	// internedEntity is an entity shared by refs cached identifiers.
+	internedEntity[T any] struct {
+		entity T
+		refs   int
 	}
 	// Telemetry for repository.
 	Telemetry[T Entity[K], K Identifier] struct {
 		Next Repository[T, K]
+		// KeyBucketer maps identifier to a low-cardinality label. Identifiers are omitted when nil.
+		KeyBucketer func(K) string
+		// Now returns current time. Defaults to time.Now.
+		Now func() time.Time
+		// Logger receives durations. Defaults to the standard logger.
+		Logger *log.Logger
 	}
+	// Debug prints repository calls when enabled in context.
+	// Output can be a BufferedWriter to coalesce lines under load or a RotatingFile to bound its size.
 	Debug[T Entity[K], K Identifier] struct {
 		Next   Repository[T, K]
 		Output io.Writer
 		Label  string
+		// Limit caps number of printed lines, see NewDebugLimit. Lines aren't limited when nil.
+		Limit *DebugLimit
+	}
+	// DebugLimit drops debug lines over a rate, counting them.
+	DebugLimit struct {
+		lock    sync.Mutex
+		bucket  *tokenBucket
+		now     func() time.Time
+		dropped atomic.Int64
 	}
 )
 
@@ -59,83 +135,399 @@ func ContextWithEnabledDebug(ctx context.Context) context.Context {
 	return context.WithValue(ctx, debugEnabler, "enabled")
 }
 
-func (d Debug[T, K]) Get(ctx context.Context, id K) (T, error) {
-	if _, ok := ctx.Value(debugEnabler).(string); ok {
-		_, _ = fmt.Fprintf(d.Output, "[DEBUG][%s] PreGet\n", d.Label)
+// NewDebugLimit limits debug lines to linesPerSecond with bursts of up to burst lines.
+// Lines are never dropped at an infinite rate.
+func NewDebugLimit(linesPerSecond float64, burst int) *DebugLimit {
+	return &DebugLimit{bucket: newTokenBucket(linesPerSecond, burst, time.Now()), now: time.Now}
+}
+
+func (l *DebugLimit) allow() bool {
+	if math.IsInf(l.bucket.rate, 1) {
+		return true
+	}
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	if l.bucket.allow(l.now()) {
+		return true
+	}
+	l.dropped.Add(1)
+	return false
+}
+
+// Dropped returns number of debug lines dropped over the limit.
+func (d Debug[T, K]) Dropped() int64 {
+	if d.Limit == nil {
+		return 0
+	}
+	return d.Limit.dropped.Load()
+}
+
+func (d Debug[T, K]) print(ctx context.Context, op string) {
+	if _, ok := ctx.Value(debugEnabler).(string); !ok {
+		return
 	}
+	if d.Limit != nil && !d.Limit.allow() {
+		return
+	}
+	_, _ = fmt.Fprintf(d.Output, "[DEBUG][%s] Pre%s\n", d.Label, op)
+}
+
+func (d Debug[T, K]) Get(ctx context.Context, id K) (T, error) {
+	d.print(ctx, "Get")
 	return d.Next.Get(ctx, id)
 }
 
 func (d Debug[T, K]) Set(ctx context.Context, entity T) error {
-	if _, ok := ctx.Value(debugEnabler).(string); ok {
-		_, _ = fmt.Fprintf(d.Output, "[DEBUG][%s] PreSet\n", d.Label)
-	}
+	d.print(ctx, "Set")
 	return d.Next.Set(ctx, entity)
 }
 
 func (d Debug[T, K]) Delete(ctx context.Context, id K) error {
-	if _, ok := ctx.Value(debugEnabler).(string); ok {
-		_, _ = fmt.Fprintf(d.Output, "[DEBUG][%s] PreDelete\n", d.Label)
-	}
+	d.print(ctx, "Delete")
 	return d.Next.Delete(ctx, id)
 }
 
+func (d Debug[T, K]) GetOrCreate(ctx context.Context, id K, create func() T) (T, error) {
+	d.print(ctx, "GetOrCreate")
+	return getOrCreate(ctx, d.Next, id, create)
+}
+
+func (d Debug[T, K]) Patch(ctx context.Context, id K, mutate func(T) (T, error)) error {
+	d.print(ctx, "Patch")
+	return patch(ctx, d.Next, id, mutate)
+}
+
+func (d Debug[T, K]) CompareAndSet(ctx context.Context, expected T, new T) (bool, error) {
+	d.print(ctx, "CompareAndSet")
+	return compareAndSet[T, K](ctx, d.Next, expected, new)
+}
+
+func (d Debug[T, K]) InTx(ctx context.Context, fn func(Repository[T, K]) error) error {
+	d.print(ctx, "InTx")
+	return inTx(ctx, d.Next, fn)
+}
+
+func (d Debug[T, K]) SelfTest(ctx context.Context) error {
+	d.print(ctx, "SelfTest")
+	return selfTest(ctx, d.Next)
+}
+
+// Get reads through to Next, refreshing cached entity, for contexts with ContextWithFreshRead.
 func (c *Cache[T, K]) Get(ctx context.Context, id K) (T, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	freshRead := isFreshRead(ctx)
 	entity, isCached := c.cached[id]
-	if isCached {
+	if isCached && !freshRead {
 		return entity, nil
 	}
+	if c.warming && !freshRead && (c.WarmingBypass == nil || !c.WarmingBypass(id)) {
+		return entity, ErrCacheWarming
+	}
 	entity, err := c.Next.Get(ctx, id)
+	if errors.Is(err, errNotFound) {
+		c.evict(id)
+	}
 	if err != nil {
 		return entity, err
 	}
-	c.cached[entity.Identifier()] = entity
-	return entity, nil
+	return c.store(id, entity), nil
+}
+
+// Warm populates cache with entities of ids. Until it's done, Get of entities not yet cached
+// returns ErrCacheWarming instead of stampeding Next, unless allowed by WarmingBypass.
+// Missing entities are skipped; other errors stop warming.
+func (c *Cache[T, K]) Warm(ctx context.Context, ids []K) error {
+	c.lock.Lock()
+	c.warming = true
+	c.lock.Unlock()
+	defer func() {
+		c.lock.Lock()
+		c.warming = false
+		c.lock.Unlock()
+	}()
+	for _, id := range ids {
+		entity, err := c.Next.Get(ctx, id)
+		if errors.Is(err, errNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("unable to warm cache: %w", err)
+		}
+		c.lock.Lock()
+		c.store(id, entity)
+		c.lock.Unlock()
+	}
+	return nil
 }
 
 func (c *Cache[T, K]) Set(ctx context.Context, entity T) error {
 	c.lock.Lock()
-	delete(c.cached, entity.Identifier())
+	c.evict(entity.Identifier())
 	c.lock.Unlock()
 	return c.Next.Set(ctx, entity)
 }
 
 func (c *Cache[T, K]) Delete(ctx context.Context, id K) error {
 	c.lock.Lock()
-	delete(c.cached, id)
+	c.evict(id)
 	c.lock.Unlock()
 	return c.Next.Delete(ctx, id)
 }
 
+func (c *Cache[T, K]) GetOrCreate(ctx context.Context, id K, create func() T) (T, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	entity, isCached := c.cached[id]
+	if isCached {
+		return entity, nil
+	}
+	entity, err := getOrCreate(ctx, c.Next, id, create)
+	if err != nil {
+		return entity, err
+	}
+	return c.store(id, entity), nil
+}
+
+// store caches entity under id and returns the cached copy, which is shared when entity is interned.
+func (c *Cache[T, K]) store(id K, entity T) T {
+	if c.Cacheable != nil && !c.Cacheable(entity) {
+		return entity
+	}
+	if _, exists := c.cached[id]; !exists && c.MaxEntries > 0 && len(c.cached) >= c.MaxEntries {
+		for victim := range c.cached {
+			c.evict(victim)
+			break
+		}
+	}
+	if c.Intern == nil {
+		c.cached[id] = entity
+		return entity
+	}
+	raw, err := c.Intern.Serialize(entity)
+	if err != nil {
+		return entity
+	}
+	c.evict(id)
+	if c.interned == nil {
+		c.interned = make(map[string]*internedEntity[T])
+		c.internedKeys = make(map[K]string)
+	}
+	shared, exists := c.interned[string(raw)]
+	if !exists {
+		shared = &internedEntity[T]{entity: entity}
+		c.interned[string(raw)] = shared
+	}
+	shared.refs++
+	c.internedKeys[id] = string(raw)
+	c.cached[id] = shared.entity
+	return shared.entity
+}
+
+// evict drops cached entity, releasing its interned copy once no identifier refers to it.
+func (c *Cache[T, K]) evict(id K) {
+	delete(c.cached, id)
+	raw, isInterned := c.internedKeys[id]
+	if !isInterned {
+		return
+	}
+	delete(c.internedKeys, id)
+	if shared := c.interned[raw]; shared.refs > 1 {
+		shared.refs--
+	} else {
+		delete(c.interned, raw)
+	}
+}
+
+func (c *Cache[T, K]) evictAll() {
+	c.cached = make(map[K]T)
+	c.interned = nil
+	c.internedKeys = nil
+}
+
+// Patch invalidates cached entity and keeps the cache locked until patch completes,
+// so a concurrent Get can't cache the entity from before the patch.
+func (c *Cache[T, K]) Patch(ctx context.Context, id K, mutate func(T) (T, error)) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.evict(id)
+	return patch(ctx, c.Next, id, mutate)
+}
+
+// CompareAndSet invalidates cached entity when it's replaced, keeping the cache locked meanwhile.
+func (c *Cache[T, K]) CompareAndSet(ctx context.Context, expected T, new T) (bool, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	swapped, err := compareAndSet[T, K](ctx, c.Next, expected, new)
+	if swapped {
+		c.evict(new.Identifier())
+	}
+	return swapped, err
+}
+
+// InTx drops all cached entities and keeps the cache locked until transaction completes,
+// as entities changed by it aren't known.
+func (c *Cache[T, K]) InTx(ctx context.Context, fn func(Repository[T, K]) error) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.evictAll()
+	return inTx(ctx, c.Next, fn)
+}
+
+// SelfTest bypasses the cache, as the sentinel entity is never cached.
+func (c *Cache[T, K]) SelfTest(ctx context.Context) error {
+	return selfTest(ctx, c.Next)
+}
+
+// Invalidate drops cached entity, e.g. after it was changed in storage bypassing the cache.
+func (c *Cache[T, K]) Invalidate(id K) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.evict(id)
+}
+
+// InvalidateAll drops all cached entities.
+func (c *Cache[T, K]) InvalidateAll() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.evictAll()
+}
+
 func (t Telemetry[T, K]) Get(ctx context.Context, id K) (T, error) {
-	sT := time.Now()
+	sT := t.now()
 	defer func() {
 		// For now log values instead of applying changes to metrics.
-		log.Printf("Get: %s", time.Since(sT))
+		t.logger().Printf("%s: %s", t.label("Get", id), t.now().Sub(sT))
 	}()
 	return t.Next.Get(ctx, id)
 }
 
 func (t Telemetry[T, K]) Set(ctx context.Context, entity T) error {
-	sT := time.Now()
+	sT := t.now()
 	defer func() {
 		// For now log values instead of applying changes to metrics.
-		log.Printf("Set: %s", time.Since(sT))
+		t.logger().Printf("%s: %s", t.label("Set", entity.Identifier()), t.now().Sub(sT))
 	}()
 	return t.Next.Set(ctx, entity)
 }
 
 func (t Telemetry[T, K]) Delete(ctx context.Context, id K) error {
-	sT := time.Now()
+	sT := t.now()
 	defer func() {
 		// For now log values instead of applying changes to metrics.
-		log.Printf("Delete: %s", time.Since(sT))
+		t.logger().Printf("%s: %s", t.label("Delete", id), t.now().Sub(sT))
 	}()
 	return t.Next.Delete(ctx, id)
 }
 
+func (t Telemetry[T, K]) GetOrCreate(ctx context.Context, id K, create func() T) (T, error) {
+	sT := t.now()
+	defer func() {
+		t.logger().Printf("%s: %s", t.label("GetOrCreate", id), t.now().Sub(sT))
+	}()
+	return getOrCreate(ctx, t.Next, id, create)
+}
+
+func (t Telemetry[T, K]) Patch(ctx context.Context, id K, mutate func(T) (T, error)) error {
+	sT := t.now()
+	defer func() {
+		t.logger().Printf("%s: %s", t.label("Patch", id), t.now().Sub(sT))
+	}()
+	return patch(ctx, t.Next, id, mutate)
+}
+
+func (t Telemetry[T, K]) CompareAndSet(ctx context.Context, expected T, new T) (bool, error) {
+	sT := t.now()
+	defer func() {
+		t.logger().Printf("%s: %s", t.label("CompareAndSet", new.Identifier()), t.now().Sub(sT))
+	}()
+	return compareAndSet[T, K](ctx, t.Next, expected, new)
+}
+
+func (t Telemetry[T, K]) InTx(ctx context.Context, fn func(Repository[T, K]) error) error {
+	sT := t.now()
+	defer func() {
+		t.logger().Printf("InTx: %s", t.now().Sub(sT))
+	}()
+	return inTx(ctx, t.Next, fn)
+}
+
+func (t Telemetry[T, K]) SelfTest(ctx context.Context) error {
+	sT := t.now()
+	defer func() {
+		t.logger().Printf("SelfTest: %s", t.now().Sub(sT))
+	}()
+	return selfTest(ctx, t.Next)
+}
+
+func (t Telemetry[T, K]) now() time.Time {
+	if t.Now == nil {
+		return time.Now()
+	}
+	return t.Now()
+}
+
+func (t Telemetry[T, K]) logger() *log.Logger {
+	if t.Logger == nil {
+		return log.Default()
+	}
+	return t.Logger
+}
+
+// label of operation including key bucket when KeyBucketer is set.
+func (t Telemetry[T, K]) label(op string, id K) string {
+	if t.KeyBucketer == nil {
+		return op
+	}
+	return op + "[" + t.KeyBucketer(id) + "]"
+}
+
+// getOrCreate forwards GetOrCreate to next repository when it supports it.
+func getOrCreate[T Entity[K], K Identifier](ctx context.Context, next Repository[T, K], id K, create func() T) (T, error) {
+	creator, ok := next.(GetOrCreator[T, K])
+	if !ok {
+		var entity T
+		return entity, fmt.Errorf("%w: GetOrCreate", errUnsupported)
+	}
+	return creator.GetOrCreate(ctx, id, create)
+}
+
+// patch forwards Patch to next repository when it supports it.
+func patch[T Entity[K], K Identifier](ctx context.Context, next Repository[T, K], id K, mutate func(T) (T, error)) error {
+	patcher, ok := next.(Patcher[T, K])
+	if !ok {
+		return fmt.Errorf("%w: Patch", errUnsupported)
+	}
+	return patcher.Patch(ctx, id, mutate)
+}
+
+// compareAndSet forwards CompareAndSet to next repository when it supports it.
+func compareAndSet[T Entity[K], K Identifier](ctx context.Context, next Repository[T, K], expected T, new T) (bool, error) {
+	setter, ok := next.(CompareAndSetter[T])
+	if !ok {
+		return false, fmt.Errorf("%w: CompareAndSet", errUnsupported)
+	}
+	return setter.CompareAndSet(ctx, expected, new)
+}
+
+// inTx forwards InTx to next repository when it supports it.
+func inTx[T Entity[K], K Identifier](ctx context.Context, next Repository[T, K], fn func(Repository[T, K]) error) error {
+	transactional, ok := next.(Transactional[T, K])
+	if !ok {
+		return fmt.Errorf("%w: InTx", errUnsupported)
+	}
+	return transactional.InTx(ctx, fn)
+}
+
+// selfTest forwards SelfTest to next repository when it supports it.
+func selfTest[T Entity[K], K Identifier](ctx context.Context, next Repository[T, K]) error {
+	tester, ok := next.(SelfTester)
+	if !ok {
+		return fmt.Errorf("%w: SelfTest", errUnsupported)
+	}
+	return tester.SelfTest(ctx)
+}
+
 func NewInMemoryRepository[T Entity[K], K Identifier](identitySerializer serializer[K], entitySerializer serializer[T]) *InMemoryRepository[T, K] {
 	return &InMemoryRepository[T, K]{
 		entities:             make(map[string][]byte),
@@ -144,7 +536,27 @@ func NewInMemoryRepository[T Entity[K], K Identifier](identitySerializer seriali
 	}
 }
 
-var errNotFound = errors.New("not found")
+var (
+	errNotFound           = errors.New("not found")
+	errUnsupported        = errors.New("operation not supported by next repository")
+	errIdentifierMismatch = errors.New("entities have different identifiers")
+	// ErrCacheWarming is returned by Cache for entities not cached yet during warm-up.
+	ErrCacheWarming = errors.New("cache is warming up")
+	// ErrSerialize wraps errors of serializing identifiers and entities.
+	ErrSerialize = errors.New("unable to serialize")
+	// ErrDeserialize wraps errors of unserializing entities.
+	ErrDeserialize = errors.New("unable to unserialize")
+	// ErrSelfTest is returned by SelfTest when a sentinel entity doesn't survive a round-trip.
+	ErrSelfTest = errors.New("self-test failed")
+)
+
+// serialize entity, in format chosen by context for serializers supporting it.
+func (i *InMemoryRepository[T, K]) serialize(ctx context.Context, entity T) ([]byte, error) {
+	if contextual, ok := i.entitySerializer.(contextSerializer[T]); ok {
+		return contextual.SerializeContext(ctx, entity)
+	}
+	return i.entitySerializer.Serialize(entity)
+}
 
 func (i *InMemoryRepository[T, K]) Get(ctx context.Context, id K) (T, error) {
 	i.lock.Lock()
@@ -152,7 +564,7 @@ func (i *InMemoryRepository[T, K]) Get(ctx context.Context, id K) (T, error) {
 	var entity T
 	key, err := i.identifierSerializer.Serialize(id)
 	if err != nil {
-		return entity, fmt.Errorf("unable to serialize identifier: %w", err)
+		return entity, fmt.Errorf("%w identifier: %w", ErrSerialize, err)
 	}
 	raw, exists := i.entities[string(key)]
 	if !exists {
@@ -160,7 +572,7 @@ func (i *InMemoryRepository[T, K]) Get(ctx context.Context, id K) (T, error) {
 	}
 	entity, err = i.entitySerializer.UnSerialize(raw)
 	if err != nil {
-		return entity, fmt.Errorf("unable to unserialize entity: %w", err)
+		return entity, fmt.Errorf("%w entity: %w", ErrDeserialize, err)
 	}
 	return entity, nil
 }
@@ -170,23 +582,277 @@ func (i *InMemoryRepository[T, K]) Set(ctx context.Context, entity T) error {
 	defer i.lock.Unlock()
 	key, err := i.identifierSerializer.Serialize(entity.Identifier())
 	if err != nil {
-		return fmt.Errorf("unable to serialize identifier: %w", err)
+		return fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	raw, err := i.serialize(ctx, entity)
+	if err != nil {
+		return fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	i.entities[string(key)] = raw
+	return nil
+}
+
+// GetOrCreate returns stored entity or stores and returns the one made by create when missing.
+// Lookup and creation happen under a single lock, so create runs once for concurrent calls.
+func (i *InMemoryRepository[T, K]) GetOrCreate(ctx context.Context, id K, create func() T) (T, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	var entity T
+	key, err := i.identifierSerializer.Serialize(id)
+	if err != nil {
+		return entity, fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	if raw, exists := i.entities[string(key)]; exists {
+		entity, err = i.entitySerializer.UnSerialize(raw)
+		if err != nil {
+			return entity, fmt.Errorf("%w entity: %w", ErrDeserialize, err)
+		}
+		return entity, nil
+	}
+	entity = create()
+	raw, err := i.serialize(ctx, entity)
+	if err != nil {
+		return entity, fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	i.entities[string(key)] = raw
+	return entity, nil
+}
+
+// Patch applies mutate to stored entity and writes the result back.
+// Repository lock is held for the whole update, so concurrent patches of an entity never interleave.
+func (i *InMemoryRepository[T, K]) Patch(ctx context.Context, id K, mutate func(T) (T, error)) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	key, err := i.identifierSerializer.Serialize(id)
+	if err != nil {
+		return fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	raw, exists := i.entities[string(key)]
+	if !exists {
+		return errNotFound
+	}
+	entity, err := i.entitySerializer.UnSerialize(raw)
+	if err != nil {
+		return fmt.Errorf("%w entity: %w", ErrDeserialize, err)
 	}
-	raw, err := i.entitySerializer.Serialize(entity)
+	entity, err = mutate(entity)
 	if err != nil {
-		return fmt.Errorf("unable to serialize entity: %w", err)
+		return err
+	}
+	raw, err = i.serialize(ctx, entity)
+	if err != nil {
+		return fmt.Errorf("%w entity: %w", ErrSerialize, err)
 	}
 	i.entities[string(key)] = raw
 	return nil
 }
 
+// CompareAndSet stores new only if serialized stored entity equals serialized expected one.
+// Both entities must have the same identifier.
+func (i *InMemoryRepository[T, K]) CompareAndSet(ctx context.Context, expected T, new T) (bool, error) {
+	if expected.Identifier() != new.Identifier() {
+		return false, errIdentifierMismatch
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	key, err := i.identifierSerializer.Serialize(new.Identifier())
+	if err != nil {
+		return false, fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	stored, exists := i.entities[string(key)]
+	if !exists {
+		return false, errNotFound
+	}
+	expectedRaw, err := i.serialize(ctx, expected)
+	if err != nil {
+		return false, fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	if !bytes.Equal(stored, expectedRaw) {
+		return false, nil
+	}
+	raw, err := i.serialize(ctx, new)
+	if err != nil {
+		return false, fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	i.entities[string(key)] = raw
+	return true, nil
+}
+
 func (i *InMemoryRepository[T, K]) Delete(ctx context.Context, id K) error {
 	i.lock.Lock()
 	defer i.lock.Unlock()
 	key, err := i.identifierSerializer.Serialize(id)
 	if err != nil {
-		return fmt.Errorf("unable to serialize identifier: %w", err)
+		return fmt.Errorf("%w identifier: %w", ErrSerialize, err)
 	}
 	delete(i.entities, string(key))
 	return nil
 }
+
+// InTx runs fn with repository lock held, restoring entities from a snapshot when fn returns an error or panics.
+// Repository passed to fn must not be used after fn returns.
+func (i *InMemoryRepository[T, K]) InTx(ctx context.Context, fn func(Repository[T, K]) error) error {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	snapshot := maps.Clone(i.entities)
+	committed := false
+	defer func() {
+		if !committed {
+			i.entities = snapshot
+		}
+	}()
+	tx := &InMemoryRepository[T, K]{
+		entities:             i.entities,
+		identifierSerializer: i.identifierSerializer,
+		entitySerializer:     i.entitySerializer,
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// SelfTest sets, gets and deletes zero entity under its own identifier, verifying it reads back unchanged.
+// An entity already stored under that identifier is restored with the lock still held, so it's never affected.
+func (i *InMemoryRepository[T, K]) SelfTest(ctx context.Context) error {
+	var sentinel T
+	key, err := i.identifierSerializer.Serialize(sentinel.Identifier())
+	if err != nil {
+		return fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	probeKey := string(key)
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	if previous, exists := i.entities[probeKey]; exists {
+		defer func() {
+			i.entities[probeKey] = previous
+		}()
+	}
+	raw, err := i.serialize(ctx, sentinel)
+	if err != nil {
+		return fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	i.entities[probeKey] = raw
+	entity, err := i.entitySerializer.UnSerialize(i.entities[probeKey])
+	delete(i.entities, probeKey)
+	if err != nil {
+		return fmt.Errorf("%w entity: %w", ErrDeserialize, err)
+	}
+	readBack, err := i.serialize(ctx, entity)
+	if err != nil {
+		return fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	if !bytes.Equal(raw, readBack) {
+		return fmt.Errorf("%w: sentinel entity changed in round-trip", ErrSelfTest)
+	}
+	return nil
+}
+
+func (i *InMemoryRepository[T, K]) Count(ctx context.Context) (int, error) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	return len(i.entities), nil
+}
+
+// Import stores entities in a single critical section, which is much faster than repeated Set for large batches.
+// Nothing is stored when any of the entities can't be serialized; the returned error lists all of them.
+func (i *InMemoryRepository[T, K]) Import(ctx context.Context, entities []T) error {
+	raws := make(map[string][]byte, len(entities))
+	var errs []error
+	for _, entity := range entities {
+		key, err := i.identifierSerializer.Serialize(entity.Identifier())
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w identifier: %w", ErrSerialize, err))
+			continue
+		}
+		raw, err := i.serialize(ctx, entity)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w entity: %w", ErrSerialize, err))
+			continue
+		}
+		raws[string(key)] = raw
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	i.lock.Lock()
+	defer i.lock.Unlock()
+	for key, raw := range raws {
+		i.entities[key] = raw
+	}
+	return nil
+}
+
+// Export writes all entities to w, each framed with its big-endian uint32 length.
+// Entities are written from a snapshot taken when Export starts.
+func (i *InMemoryRepository[T, K]) Export(ctx context.Context, w io.Writer) error {
+	i.lock.Lock()
+	raws := make([][]byte, 0, len(i.entities))
+	for _, raw := range i.entities {
+		raws = append(raws, raw)
+	}
+	i.lock.Unlock()
+	for _, raw := range raws {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := writeFrame(w, raw); err != nil {
+			return fmt.Errorf("unable to write entity: %w", err)
+		}
+	}
+	return nil
+}
+
+// ImportFrom restores entities written by Export. Nothing is stored when reading fails.
+func (i *InMemoryRepository[T, K]) ImportFrom(ctx context.Context, r io.Reader) error {
+	var entities []T
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		raw, err := readFrame(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read entity: %w", err)
+		}
+		entity, err := i.entitySerializer.UnSerialize(raw)
+		if err != nil {
+			return fmt.Errorf("%w entity: %w", ErrDeserialize, err)
+		}
+		entities = append(entities, entity)
+	}
+	return i.Import(ctx, entities)
+}
+
+func writeFrame(w io.Writer, raw []byte) error {
+	if err := binary.Write(w, binary.BigEndian, uint32(len(raw))); err != nil {
+		return err
+	}
+	_, err := w.Write(raw)
+	return err
+}
+
+// maxFrameSize limits memory allocated for a single frame read from an untrusted stream.
+const maxFrameSize = 64 << 20
+
+// readFrame returns io.EOF only when there are no more frames; a truncated frame is io.ErrUnexpectedEOF.
+func readFrame(r io.Reader) ([]byte, error) {
+	var size uint32
+	if err := binary.Read(r, binary.BigEndian, &size); err != nil {
+		return nil, err
+	}
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("%w: frame of %d bytes exceeds limit", ErrDeserialize, size)
+	}
+	raw := make([]byte, size)
+	if _, err := io.ReadFull(r, raw); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return raw, nil
+}
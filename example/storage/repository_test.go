@@ -0,0 +1,514 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/jlisicki/middlewarebuilder"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// stubRepository records calls and delegates them to optional functions.
+type stubRepository struct {
+	lock       sync.Mutex
+	calls      []string
+	getFunc    func(ctx context.Context, id UserID) (User, error)
+	setFunc    func(ctx context.Context, entity User) error
+	deleteFunc func(ctx context.Context, id UserID) error
+}
+
+func (s *stubRepository) Get(ctx context.Context, id UserID) (User, error) {
+	s.record("Get")
+	if s.getFunc == nil {
+		return User{}, errNotFound
+	}
+	return s.getFunc(ctx, id)
+}
+
+func (s *stubRepository) Set(ctx context.Context, entity User) error {
+	s.record("Set")
+	if s.setFunc == nil {
+		return nil
+	}
+	return s.setFunc(ctx, entity)
+}
+
+func (s *stubRepository) Delete(ctx context.Context, id UserID) error {
+	s.record("Delete")
+	if s.deleteFunc == nil {
+		return nil
+	}
+	return s.deleteFunc(ctx, id)
+}
+
+func (s *stubRepository) record(op string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.calls = append(s.calls, op)
+}
+
+func (s *stubRepository) count(op string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	n := 0
+	for _, call := range s.calls {
+		if call == op {
+			n++
+		}
+	}
+	return n
+}
+
+var (
+	errExample          = errors.New("example error")
+	errBrokenSerializer = errors.New("broken serializer")
+	discardLogger       = log.New(io.Discard, "", 0)
+)
+
+// newQuietUserRepository builds the stack of NewUserRepository with telemetry logged to discardLogger.
+func newQuietUserRepository(t *testing.T) UserRepository {
+	repo, err := middlewarebuilder.NewBuilder[UserRepository]().
+		Add(TelemetryFactory[User, UserID](TelemetryOptions{Logger: discardLogger})).
+		Add(DebugFactory[User, UserID](DebugOptions{Output: io.Discard, Label: "CacheCall"})).
+		Add(CacheFactory[User, UserID](CacheOptions{MaxEntries: 1000})).
+		Add(DebugFactory[User, UserID](DebugOptions{Output: io.Discard, Label: "StorageCall"})).
+		WithHandler(NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})).
+		Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return repo
+}
+
+type (
+	counter struct {
+		ID    UserID
+		Value int
+	}
+	counterSerializer       struct{}
+	failingUserIDSerializer struct{}
+	failingUserSerializer   struct {
+		failSerialize bool
+	}
+)
+
+func (c counter) Identifier() UserID {
+	return c.ID
+}
+
+func (c counterSerializer) Serialize(t counter) ([]byte, error) {
+	return json.Marshal(t)
+}
+
+func (c counterSerializer) UnSerialize(bytes []byte) (counter, error) {
+	var t counter
+	err := json.Unmarshal(bytes, &t)
+	return t, err
+}
+
+func (f failingUserIDSerializer) Serialize(UserID) ([]byte, error) {
+	return nil, errBrokenSerializer
+}
+
+func (f failingUserIDSerializer) UnSerialize([]byte) (UserID, error) {
+	return "", errBrokenSerializer
+}
+
+func (f failingUserSerializer) Serialize(u User) ([]byte, error) {
+	if f.failSerialize {
+		return nil, errBrokenSerializer
+	}
+	return userSerializer{}.Serialize(u)
+}
+
+func (f failingUserSerializer) UnSerialize([]byte) (User, error) {
+	return User{}, errBrokenSerializer
+}
+
+func TestInMemoryRepository_serializationErrors(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should classify identifier serialization errors", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](failingUserIDSerializer{}, userSerializer{})
+		_, getErr := repo.Get(ctx, "10")
+		for _, err := range []error{getErr, repo.Set(ctx, User{ID: "10"}), repo.Delete(ctx, "10")} {
+			if !errors.Is(err, ErrSerialize) || !errors.Is(err, errBrokenSerializer) {
+				t.Errorf("Expected serialize error wrapping cause but got: %v", err)
+			}
+		}
+	})
+	t.Run("Should classify entity serialization errors", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, failingUserSerializer{failSerialize: true})
+		err := repo.Set(ctx, User{ID: "10"})
+		if !errors.Is(err, ErrSerialize) || !errors.Is(err, errBrokenSerializer) {
+			t.Errorf("Expected serialize error wrapping cause but got: %v", err)
+		}
+	})
+	t.Run("Should classify entity deserialization errors", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, failingUserSerializer{})
+		_ = repo.Set(ctx, User{ID: "10"})
+		_, err := repo.Get(ctx, "10")
+		if !errors.Is(err, ErrDeserialize) || errors.Is(err, ErrSerialize) || errors.Is(err, errNotFound) {
+			t.Errorf("Expected only deserialize error but got: %v", err)
+		}
+		if !errors.Is(err, errBrokenSerializer) {
+			t.Errorf("Expected error wrapping cause but got: %v", err)
+		}
+	})
+}
+
+func TestInMemoryRepository_Import(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should store all entities", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		users := make([]User, 0, 1000)
+		for i := 0; i < 1000; i++ {
+			users = append(users, User{ID: UserID(fmt.Sprint(i)), Name: fmt.Sprintf("user %d", i)})
+		}
+		if err := repo.Import(ctx, users); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		for _, id := range []UserID{"0", "500", "999"} {
+			user, err := repo.Get(ctx, id)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if expected := "user " + string(id); user.Name != expected {
+				t.Errorf("Got '%s' but expected '%s'", user.Name, expected)
+			}
+		}
+	})
+	t.Run("Should store nothing and return serialization errors", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, failingUserSerializer{failSerialize: true})
+		err := repo.Import(ctx, []User{{ID: "10"}, {ID: "11"}})
+		if !errors.Is(err, ErrSerialize) {
+			t.Fatalf("Expected serialize error but got: %v", err)
+		}
+		if n := len(err.(interface{ Unwrap() []error }).Unwrap()); n != 2 {
+			t.Errorf("Got %d errors but expected 2", n)
+		}
+		if len(repo.entities) != 0 {
+			t.Errorf("Expected no entities stored but got %d", len(repo.entities))
+		}
+	})
+}
+
+func TestInMemoryRepository_Export(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should restore exported entities into fresh repository", func(t *testing.T) {
+		source := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		_ = source.Set(ctx, User{ID: "10", Name: "John"})
+		_ = source.Set(ctx, User{ID: "11", Name: "Jane"})
+		var buf bytes.Buffer
+		if err := source.Export(ctx, &buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		target := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		if err := target.ImportFrom(ctx, &buf); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		for _, expected := range []User{{ID: "10", Name: "John"}, {ID: "11", Name: "Jane"}} {
+			user, err := target.Get(ctx, expected.ID)
+			if err != nil || user != expected {
+				t.Errorf("Got %v, %v but expected %v", user, err, expected)
+			}
+		}
+	})
+	t.Run("Should stop on cancelled context", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		_ = repo.Set(ctx, User{ID: "10"})
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		var buf bytes.Buffer
+		if err := repo.Export(cancelled, &buf); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context cancelled error but got: %v", err)
+		}
+		if err := repo.ImportFrom(cancelled, &buf); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context cancelled error but got: %v", err)
+		}
+	})
+	t.Run("Should return error for truncated stream", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		err := repo.ImportFrom(ctx, bytes.NewReader([]byte{0, 0, 0, 10, '{'}))
+		if !errors.Is(err, io.ErrUnexpectedEOF) {
+			t.Errorf("Expected unexpected EOF error but got: %v", err)
+		}
+	})
+	t.Run("Should reject oversized frame", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		err := repo.ImportFrom(ctx, bytes.NewReader([]byte{0xff, 0xff, 0xff, 0xff}))
+		if !errors.Is(err, ErrDeserialize) {
+			t.Errorf("Expected deserialize error but got: %v", err)
+		}
+	})
+}
+
+func TestGetOrCreate(t *testing.T) {
+	ctx := ContextWithEnabledDebug(context.Background())
+	t.Run("Should create missing entity once for concurrent calls", func(t *testing.T) {
+		var created int32
+		creator := newQuietUserRepository(t).(GetOrCreator[User, UserID])
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				user, err := creator.GetOrCreate(ctx, "10", func() User {
+					atomic.AddInt32(&created, 1)
+					return User{ID: "10", Name: "John"}
+				})
+				if err != nil || user.Name != "John" {
+					t.Errorf("Got %v, %v but expected created user", user, err)
+				}
+			}()
+		}
+		wg.Wait()
+		if created != 1 {
+			t.Errorf("Got %d creations but expected 1", created)
+		}
+	})
+	t.Run("Should return existing entity", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		user, err := repo.GetOrCreate(ctx, "10", func() User {
+			t.Error("Unexpected creation of existing entity")
+			return User{}
+		})
+		if err != nil || user.Name != "John" {
+			t.Errorf("Got %v, %v but expected existing user", user, err)
+		}
+	})
+	t.Run("Should return error when next repository doesn't support it", func(t *testing.T) {
+		repo := Telemetry[User, UserID]{Next: &stubRepository{}, Logger: discardLogger}
+		_, err := repo.GetOrCreate(ctx, "10", func() User { return User{} })
+		if !errors.Is(err, errUnsupported) {
+			t.Errorf("Expected unsupported error but got: %v", err)
+		}
+	})
+}
+
+func TestPatch(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should apply concurrent patches atomically", func(t *testing.T) {
+		cache := &Cache[counter, UserID]{
+			Next:   NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{}),
+			cached: make(map[UserID]counter),
+		}
+		_ = cache.Set(ctx, counter{ID: "10"})
+		_, _ = cache.Get(ctx, "10")
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				err := cache.Patch(ctx, "10", func(c counter) (counter, error) {
+					c.Value++
+					return c, nil
+				})
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+			}()
+		}
+		wg.Wait()
+		c, _ := cache.Get(ctx, "10")
+		if c.Value != 50 {
+			t.Errorf("Got %d but expected 50", c.Value)
+		}
+	})
+	t.Run("Should return not found for missing entity", func(t *testing.T) {
+		repo := NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{})
+		err := repo.Patch(ctx, "10", func(c counter) (counter, error) {
+			return c, nil
+		})
+		if !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+	})
+	t.Run("Should keep entity when mutation fails", func(t *testing.T) {
+		repo := NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{})
+		_ = repo.Set(ctx, counter{ID: "10", Value: 1})
+		err := repo.Patch(ctx, "10", func(c counter) (counter, error) {
+			c.Value++
+			return c, errExample
+		})
+		if !errors.Is(err, errExample) {
+			t.Errorf("Expected example error but got: %v", err)
+		}
+		if c, _ := repo.Get(ctx, "10"); c.Value != 1 {
+			t.Errorf("Got %d but expected 1", c.Value)
+		}
+	})
+}
+
+func TestInTx(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should commit all operations", func(t *testing.T) {
+		repo := NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{})
+		_ = repo.Set(ctx, counter{ID: "10", Value: 1})
+		err := repo.InTx(ctx, func(tx Repository[counter, UserID]) error {
+			if err := tx.Set(ctx, counter{ID: "11", Value: 2}); err != nil {
+				return err
+			}
+			return tx.Delete(ctx, "10")
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+		if c, _ := repo.Get(ctx, "11"); c.Value != 2 {
+			t.Errorf("Got %d but expected 2", c.Value)
+		}
+	})
+	t.Run("Should roll back all operations when transaction fails", func(t *testing.T) {
+		repo := NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{})
+		_ = repo.Set(ctx, counter{ID: "10", Value: 1})
+		err := repo.InTx(ctx, func(tx Repository[counter, UserID]) error {
+			_ = tx.Set(ctx, counter{ID: "10", Value: 5})
+			_ = tx.Set(ctx, counter{ID: "11", Value: 2})
+			return errExample
+		})
+		if !errors.Is(err, errExample) {
+			t.Errorf("Expected example error but got: %v", err)
+		}
+		if c, _ := repo.Get(ctx, "10"); c.Value != 1 {
+			t.Errorf("Got %d but expected 1", c.Value)
+		}
+		if _, err := repo.Get(ctx, "11"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+	})
+	t.Run("Should forward transaction through decorators", func(t *testing.T) {
+		var repo Repository[counter, UserID] = NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{})
+		cache := &Cache[counter, UserID]{Next: repo, cached: make(map[UserID]counter)}
+		repo = Debug[counter, UserID]{Next: cache, Output: io.Discard}
+		_ = repo.Set(ctx, counter{ID: "10", Value: 1})
+		_, _ = repo.Get(ctx, "10")
+		err := repo.(Transactional[counter, UserID]).InTx(ctx, func(tx Repository[counter, UserID]) error {
+			return tx.Set(ctx, counter{ID: "10", Value: 2})
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if c, _ := repo.Get(ctx, "10"); c.Value != 2 {
+			t.Errorf("Got %d from cache but expected 2", c.Value)
+		}
+	})
+	t.Run("Should report unsupported transactions", func(t *testing.T) {
+		repo := Debug[User, UserID]{Next: &stubRepository{}, Output: io.Discard}
+		err := repo.InTx(ctx, func(tx Repository[User, UserID]) error {
+			return nil
+		})
+		if !errors.Is(err, errUnsupported) {
+			t.Errorf("Expected unsupported error but got: %v", err)
+		}
+	})
+}
+
+func TestCompareAndSet(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should let single competing CAS win", func(t *testing.T) {
+		repo := NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{})
+		_ = repo.Set(ctx, counter{ID: "10"})
+		var wg sync.WaitGroup
+		var lock sync.Mutex
+		wins := 0
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				swapped, err := repo.CompareAndSet(ctx, counter{ID: "10"}, counter{ID: "10", Value: i + 1})
+				if err != nil {
+					t.Errorf("Unexpected error: %s", err)
+				}
+				if swapped {
+					lock.Lock()
+					wins++
+					lock.Unlock()
+				}
+			}(i)
+		}
+		wg.Wait()
+		if wins != 1 {
+			t.Errorf("Got %d successful CAS but expected 1", wins)
+		}
+	})
+	t.Run("Should apply concurrent CAS retry loops atomically", func(t *testing.T) {
+		var repo Repository[counter, UserID] = NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{})
+		repo = &Cache[counter, UserID]{Next: repo, cached: make(map[UserID]counter)}
+		_ = repo.Set(ctx, counter{ID: "10"})
+		var wg sync.WaitGroup
+		for i := 0; i < 50; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					current, _ := repo.Get(ctx, "10")
+					next := counter{ID: "10", Value: current.Value + 1}
+					if swapped, _ := repo.(CompareAndSetter[counter]).CompareAndSet(ctx, current, next); swapped {
+						return
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		if c, _ := repo.Get(ctx, "10"); c.Value != 50 {
+			t.Errorf("Got %d but expected 50", c.Value)
+		}
+	})
+	t.Run("Should reject CAS of missing entity", func(t *testing.T) {
+		repo := NewInMemoryRepository[counter, UserID](userIDSerializer{}, counterSerializer{})
+		if swapped, err := repo.CompareAndSet(ctx, counter{ID: "10"}, counter{ID: "10", Value: 1}); swapped || !errors.Is(err, errNotFound) {
+			t.Errorf("Got %t, %v but expected not found error", swapped, err)
+		}
+	})
+}
+
+func TestSelfTest(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should succeed through healthy stack", func(t *testing.T) {
+		repo := newQuietUserRepository(t)
+		if err := repo.(SelfTester).SelfTest(ctx); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should leave stored entities intact", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		_ = repo.Set(ctx, User{ID: "10"})
+		_ = repo.SelfTest(ctx)
+		if count, _ := repo.Count(ctx); count != 1 {
+			t.Errorf("Got %d entities but expected 1", count)
+		}
+	})
+	t.Run("Should restore entity stored under probe identifier", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		_ = repo.Set(ctx, User{Name: "zero"})
+		if err := repo.SelfTest(ctx); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user, err := repo.Get(ctx, ""); err != nil || user.Name != "zero" {
+			t.Errorf("Got %v, %v but expected stored entity", user, err)
+		}
+	})
+	t.Run("Should fail with broken serializer", func(t *testing.T) {
+		var repo Repository[User, UserID] = NewInMemoryRepository[User, UserID](userIDSerializer{}, failingUserSerializer{})
+		repo = &Cache[User, UserID]{Next: repo, cached: make(map[UserID]User)}
+		if err := repo.(SelfTester).SelfTest(ctx); !errors.Is(err, ErrDeserialize) {
+			t.Errorf("Expected unserialize error but got: %v", err)
+		}
+	})
+	t.Run("Should fail when next doesn't support it", func(t *testing.T) {
+		repo := Debug[User, UserID]{Next: &stubRepository{}, Output: io.Discard}
+		if err := repo.SelfTest(ctx); !errors.Is(err, errUnsupported) {
+			t.Errorf("Expected unsupported error but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// RequireContext fails fast when any of required context values is missing.
+type RequireContext[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+	Keys []any
+	// Present reports whether value read for a key is acceptable. Defaults to non-nil check.
+	Present func(key, value any) bool
+}
+
+// ErrMissingContext is returned by RequireContext for missing context values.
+var ErrMissingContext = errors.New("missing required context value")
+
+func (r RequireContext[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if err := r.check(ctx); err != nil {
+		var entity T
+		return entity, err
+	}
+	return r.Next.Get(ctx, id)
+}
+
+func (r RequireContext[T, K]) Set(ctx context.Context, entity T) error {
+	if err := r.check(ctx); err != nil {
+		return err
+	}
+	return r.Next.Set(ctx, entity)
+}
+
+func (r RequireContext[T, K]) Delete(ctx context.Context, id K) error {
+	if err := r.check(ctx); err != nil {
+		return err
+	}
+	return r.Next.Delete(ctx, id)
+}
+
+func (r RequireContext[T, K]) check(ctx context.Context) error {
+	for _, key := range r.Keys {
+		value := ctx.Value(key)
+		present := value != nil
+		if r.Present != nil {
+			present = r.Present(key, value)
+		}
+		if !present {
+			return fmt.Errorf("%w: %v", ErrMissingContext, key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRequireContext(t *testing.T) {
+	t.Run("Should call next repository when values are present", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := RequireContext[User, UserID]{Next: next, Keys: []any{tenantKey}}
+		ctx := ContextWithTenant(context.Background(), "a")
+		if err := repo.Set(ctx, User{ID: "10"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if next.count("Set") != 1 {
+			t.Error("Expected call of next repository")
+		}
+	})
+	t.Run("Should return error without calling next repository when value is missing", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := RequireContext[User, UserID]{Next: next, Keys: []any{tenantKey}}
+		if _, err := repo.Get(context.Background(), "10"); !errors.Is(err, ErrMissingContext) {
+			t.Errorf("Expected missing context error but got: %v", err)
+		}
+		if err := repo.Delete(context.Background(), "10"); !errors.Is(err, ErrMissingContext) {
+			t.Errorf("Expected missing context error but got: %v", err)
+		}
+		if len(next.calls) != 0 {
+			t.Errorf("Unexpected calls of next repository: %v", next.calls)
+		}
+	})
+	t.Run("Should use predicate to check values", func(t *testing.T) {
+		repo := RequireContext[User, UserID]{
+			Next: &stubRepository{},
+			Keys: []any{tenantKey},
+			Present: func(key, value any) bool {
+				tenant, _ := value.(string)
+				return tenant != ""
+			},
+		}
+		ctx := ContextWithTenant(context.Background(), "")
+		if err := repo.Delete(ctx, "10"); !errors.Is(err, ErrMissingContext) {
+			t.Errorf("Expected missing context error but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	ctx := ContextWithEnabledDebug(context.Background())
+	line := "[DEBUG][Rotating] PreGet\n"
+
+	t.Run("Should rotate debug output after size threshold", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "debug.log")
+		out, err := NewRotatingFile(path, int64(2*len(line)), 2)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		defer out.Close()
+		repo := Debug[User, UserID]{Next: &stubRepository{}, Output: out, Label: "Rotating"}
+		for i := 0; i < 7; i++ {
+			_, _ = repo.Get(ctx, "10")
+		}
+
+		expected := map[string]int{path: 1, path + ".1": 2, path + ".2": 2}
+		for file, lines := range expected {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			if got := strings.Count(string(content), line); got != lines {
+				t.Errorf("Got %d lines in %s but expected %d", got, filepath.Base(file), lines)
+			}
+		}
+		if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+			t.Errorf("Expected backups over limit to be removed but got: %v", err)
+		}
+	})
+	t.Run("Should continue existing file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "debug.log")
+		_ = os.WriteFile(path, []byte(line), 0o644)
+		out, err := NewRotatingFile(path, int64(2*len(line)), 0)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		defer out.Close()
+		_, _ = out.Write([]byte(line))
+		_, _ = out.Write([]byte(line))
+		content, _ := os.ReadFile(path)
+		if got := strings.Count(string(content), line); got != 1 {
+			t.Errorf("Got %d lines but expected 1 after rotation without backups", got)
+		}
+		if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+			t.Errorf("Expected no backups but got: %v", err)
+		}
+	})
+}
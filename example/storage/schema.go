@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+type (
+	// Versioned is implemented by entities aware of their schema version.
+	Versioned interface {
+		SchemaVersion() int
+	}
+	// VersionStamper is implemented by entities which schema version can be set on write.
+	VersionStamper[T any] interface {
+		WithSchemaVersion(version int) T
+	}
+	VersionedEntity[K Identifier] interface {
+		Entity[K]
+		Versioned
+	}
+
+	// SchemaGuard rejects entities written with a newer schema than the one supported.
+	SchemaGuard[T VersionedEntity[K], K Identifier] struct {
+		Next Repository[T, K]
+		// MaxVersion is the newest schema version that can be read.
+		MaxVersion int
+		// CurrentVersion is stamped on entities implementing VersionStamper on Set. Zero disables stamping.
+		CurrentVersion int
+	}
+)
+
+// ErrSchemaMismatch is returned by SchemaGuard for entities with unsupported schema version.
+var ErrSchemaMismatch = errors.New("unsupported schema version")
+
+func (s SchemaGuard[T, K]) Get(ctx context.Context, id K) (T, error) {
+	entity, err := s.Next.Get(ctx, id)
+	if err != nil {
+		return entity, err
+	}
+	if version := entity.SchemaVersion(); version > s.MaxVersion {
+		var zero T
+		return zero, fmt.Errorf("%w: stored version %d exceeds %d", ErrSchemaMismatch, version, s.MaxVersion)
+	}
+	return entity, nil
+}
+
+func (s SchemaGuard[T, K]) Set(ctx context.Context, entity T) error {
+	if stamper, ok := any(entity).(VersionStamper[T]); ok && s.CurrentVersion > 0 {
+		entity = stamper.WithSchemaVersion(s.CurrentVersion)
+	}
+	return s.Next.Set(ctx, entity)
+}
+
+func (s SchemaGuard[T, K]) Delete(ctx context.Context, id K) error {
+	return s.Next.Delete(ctx, id)
+}
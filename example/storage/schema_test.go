@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+type (
+	versionedUser struct {
+		ID      UserID
+		Version int
+	}
+	versionedUserSerializer struct{}
+)
+
+func (u versionedUser) Identifier() UserID {
+	return u.ID
+}
+
+func (u versionedUser) SchemaVersion() int {
+	return u.Version
+}
+
+func (u versionedUser) WithSchemaVersion(version int) versionedUser {
+	u.Version = version
+	return u
+}
+
+func (s versionedUserSerializer) Serialize(u versionedUser) ([]byte, error) {
+	return json.Marshal(u)
+}
+
+func (s versionedUserSerializer) UnSerialize(bytes []byte) (versionedUser, error) {
+	var user versionedUser
+	err := json.Unmarshal(bytes, &user)
+	return user, err
+}
+
+func TestSchemaGuard(t *testing.T) {
+	ctx := context.Background()
+	storage := NewInMemoryRepository[versionedUser, UserID](userIDSerializer{}, versionedUserSerializer{})
+	repo := SchemaGuard[versionedUser, UserID]{Next: storage, MaxVersion: 2, CurrentVersion: 2}
+
+	t.Run("Should stamp current version on Set", func(t *testing.T) {
+		_ = repo.Set(ctx, versionedUser{ID: "10"})
+		user, _ := storage.Get(ctx, "10")
+		if user.Version != 2 {
+			t.Errorf("Got version %d but expected 2", user.Version)
+		}
+	})
+	t.Run("Should return entity with compatible version", func(t *testing.T) {
+		_ = storage.Set(ctx, versionedUser{ID: "11", Version: 1})
+		user, err := repo.Get(ctx, "11")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user.ID != "11" {
+			t.Errorf("Got '%s' but expected '11'", user.ID)
+		}
+	})
+	t.Run("Should return error for newer version", func(t *testing.T) {
+		_ = storage.Set(ctx, versionedUser{ID: "12", Version: 3})
+		_, err := repo.Get(ctx, "12")
+		if !errors.Is(err, ErrSchemaMismatch) {
+			t.Errorf("Expected schema mismatch error but got: %v", err)
+		}
+	})
+}
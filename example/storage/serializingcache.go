@@ -0,0 +1,105 @@
+package storage
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"sync"
+)
+
+type (
+	// SerializingCache for repository in local memory storing serialized entities.
+	// Every hit is unserialized again, so callers never share a cached instance.
+	SerializingCache[T Entity[K], K Identifier] struct {
+		Next Repository[T, K]
+		// MaxBytes bounds MemoryFootprint by evicting least recently used entities. Cache is unbounded when zero.
+		MaxBytes   int64
+		serializer serializer[T]
+		cached     map[K]*list.Element
+		lru        *list.List
+		bytes      int64
+		lock       sync.Mutex
+	}
+
+	serializedEntry[K Identifier] struct {
+		id  K
+		raw []byte
+	}
+)
+
+func NewSerializingCache[T Entity[K], K Identifier](next Repository[T, K], entitySerializer serializer[T]) *SerializingCache[T, K] {
+	return &SerializingCache[T, K]{
+		Next:       next,
+		serializer: entitySerializer,
+		cached:     make(map[K]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+func (c *SerializingCache[T, K]) Get(ctx context.Context, id K) (T, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	element, isCached := c.cached[id]
+	if isCached {
+		c.lru.MoveToFront(element)
+		entity, err := c.serializer.UnSerialize(element.Value.(serializedEntry[K]).raw)
+		if err != nil {
+			return entity, fmt.Errorf("%w entity: %w", ErrDeserialize, err)
+		}
+		return entity, nil
+	}
+	entity, err := c.Next.Get(ctx, id)
+	if err != nil {
+		return entity, err
+	}
+	raw, err := c.serializer.Serialize(entity)
+	if err != nil {
+		return entity, fmt.Errorf("%w entity: %w", ErrSerialize, err)
+	}
+	c.store(entity.Identifier(), raw)
+	return entity, nil
+}
+
+func (c *SerializingCache[T, K]) Set(ctx context.Context, entity T) error {
+	c.lock.Lock()
+	c.evict(entity.Identifier())
+	c.lock.Unlock()
+	return c.Next.Set(ctx, entity)
+}
+
+func (c *SerializingCache[T, K]) Delete(ctx context.Context, id K) error {
+	c.lock.Lock()
+	c.evict(id)
+	c.lock.Unlock()
+	return c.Next.Delete(ctx, id)
+}
+
+// MemoryFootprint returns total size of cached serialized entities in bytes.
+func (c *SerializingCache[T, K]) MemoryFootprint() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.bytes
+}
+
+// store caches raw entity, evicting least recently used ones over MaxBytes. Entities larger than MaxBytes aren't cached.
+func (c *SerializingCache[T, K]) store(id K, raw []byte) {
+	if c.MaxBytes > 0 && int64(len(raw)) > c.MaxBytes {
+		return
+	}
+	c.evict(id)
+	c.cached[id] = c.lru.PushFront(serializedEntry[K]{id: id, raw: raw})
+	c.bytes += int64(len(raw))
+	for c.MaxBytes > 0 && c.bytes > c.MaxBytes {
+		c.evict(c.lru.Back().Value.(serializedEntry[K]).id)
+	}
+}
+
+func (c *SerializingCache[T, K]) evict(id K) {
+	element, isCached := c.cached[id]
+	if !isCached {
+		return
+	}
+	c.lru.Remove(element)
+	delete(c.cached, id)
+	c.bytes -= int64(len(element.Value.(serializedEntry[K]).raw))
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+type (
+	taggedUser struct {
+		ID   UserID
+		Tags []string
+	}
+	taggedUserSerializer struct{}
+)
+
+func (u taggedUser) Identifier() UserID {
+	return u.ID
+}
+
+func (t taggedUserSerializer) Serialize(u taggedUser) ([]byte, error) {
+	return json.Marshal(u)
+}
+
+func (t taggedUserSerializer) UnSerialize(bytes []byte) (taggedUser, error) {
+	var user taggedUser
+	err := json.Unmarshal(bytes, &user)
+	return user, err
+}
+
+func TestSerializingCache_Get(t *testing.T) {
+	t.Run("Should return independent copies of cached entity", func(t *testing.T) {
+		ctx := context.Background()
+		storage := NewInMemoryRepository[taggedUser, UserID](userIDSerializer{}, taggedUserSerializer{})
+		cache := NewSerializingCache[taggedUser, UserID](storage, taggedUserSerializer{})
+		_ = cache.Set(ctx, taggedUser{ID: "10", Tags: []string{"admin"}})
+
+		first, err := cache.Get(ctx, "10")
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		first.Tags[0] = "mutated"
+		_ = storage.Delete(ctx, "10")
+
+		second, err := cache.Get(ctx, "10")
+		if err != nil {
+			t.Fatalf("Expected cache hit but got error: %s", err)
+		}
+		if second.Tags[0] != "admin" {
+			t.Errorf("Got '%s' but expected 'admin'", second.Tags[0])
+		}
+	})
+}
+
+func TestSerializingCache_MemoryFootprint(t *testing.T) {
+	ctx := context.Background()
+	next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+		return User{ID: id, Name: "John"}, nil
+	}}
+	size := func(id UserID) int64 {
+		raw, _ := userSerializer{}.Serialize(User{ID: id, Name: "John"})
+		return int64(len(raw))
+	}
+
+	t.Run("Should track inserts and evictions", func(t *testing.T) {
+		cache := NewSerializingCache[User, UserID](next, userSerializer{})
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "11")
+		_, _ = cache.Get(ctx, "10")
+		if got, expected := cache.MemoryFootprint(), size("10")+size("11"); got != expected {
+			t.Errorf("Got %d bytes but expected %d", got, expected)
+		}
+		_ = cache.Delete(ctx, "10")
+		_ = cache.Set(ctx, User{ID: "12"})
+		if got, expected := cache.MemoryFootprint(), size("11"); got != expected {
+			t.Errorf("Got %d bytes but expected %d", got, expected)
+		}
+	})
+	t.Run("Should evict least recently used entities over MaxBytes", func(t *testing.T) {
+		cache := NewSerializingCache[User, UserID](next, userSerializer{})
+		cache.MaxBytes = 2 * size("10")
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "11")
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "12")
+		if got := cache.MemoryFootprint(); got > cache.MaxBytes {
+			t.Errorf("Got %d bytes but expected at most %d", got, cache.MaxBytes)
+		}
+		calls := next.count("Get")
+		_, _ = cache.Get(ctx, "10")
+		_, _ = cache.Get(ctx, "12")
+		if got := next.count("Get"); got != calls {
+			t.Errorf("Got %d downstream Gets but expected recently used entities to stay cached", got-calls)
+		}
+		_, _ = cache.Get(ctx, "11")
+		if got := next.count("Get"); got != calls+1 {
+			t.Errorf("Got %d downstream Gets but expected least recently used entity to be evicted", got-calls)
+		}
+	})
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type (
+	// SessionConsistency lets sessions read their own writes. For Window after a session writes an entity,
+	// its reads of the entity are marked with ContextWithFreshRead, making Cache and other layers honoring it
+	// read from the source of truth. Operations without session in context aren't tracked.
+	SessionConsistency[T Entity[K], K Identifier] struct {
+		Next   Repository[T, K]
+		Window time.Duration
+		now    func() time.Time
+		lock   sync.Mutex
+		writes map[sessionWrite[K]]time.Time
+		// sweepAt is number of tracked writes at which expired ones are dropped, keeping writes amortized O(1).
+		sweepAt int
+	}
+
+	sessionWrite[K Identifier] struct {
+		session string
+		id      K
+	}
+)
+
+type sessionCtxKey string
+
+var (
+	sessionKey   sessionCtxKey = "session"
+	freshReadKey sessionCtxKey = "freshRead"
+)
+
+// ContextWithSession sets session of operations in context.
+func ContextWithSession(ctx context.Context, session string) context.Context {
+	return context.WithValue(ctx, sessionKey, session)
+}
+
+// ContextWithFreshRead asks layers serving possibly stale entities, like Cache, to read through.
+func ContextWithFreshRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, freshReadKey, "enabled")
+}
+
+func isFreshRead(ctx context.Context) bool {
+	_, ok := ctx.Value(freshReadKey).(string)
+	return ok
+}
+
+func NewSessionConsistency[T Entity[K], K Identifier](next Repository[T, K], window time.Duration) *SessionConsistency[T, K] {
+	return &SessionConsistency[T, K]{
+		Next:   next,
+		Window: window,
+		now:    time.Now,
+		writes: make(map[sessionWrite[K]]time.Time),
+	}
+}
+
+// minSweepAt is number of tracked entries below which expired ones aren't swept, e.g. by SessionConsistency or WriteQuota.
+const minSweepAt = 64
+
+func (s *SessionConsistency[T, K]) Get(ctx context.Context, id K) (T, error) {
+	if s.recentlyWritten(ctx, id) {
+		ctx = ContextWithFreshRead(ctx)
+	}
+	return s.Next.Get(ctx, id)
+}
+
+func (s *SessionConsistency[T, K]) Set(ctx context.Context, entity T) error {
+	if err := s.Next.Set(ctx, entity); err != nil {
+		return err
+	}
+	s.recordWrite(ctx, entity.Identifier())
+	return nil
+}
+
+func (s *SessionConsistency[T, K]) Delete(ctx context.Context, id K) error {
+	if err := s.Next.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.recordWrite(ctx, id)
+	return nil
+}
+
+func (s *SessionConsistency[T, K]) recordWrite(ctx context.Context, id K) {
+	session, ok := ctx.Value(sessionKey).(string)
+	if !ok {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	now := s.now()
+	s.writes[sessionWrite[K]{session: session, id: id}] = now
+	if len(s.writes) < max(s.sweepAt, minSweepAt) {
+		return
+	}
+	for write, at := range s.writes {
+		if now.Sub(at) >= s.Window {
+			delete(s.writes, write)
+		}
+	}
+	s.sweepAt = 2 * len(s.writes)
+}
+
+func (s *SessionConsistency[T, K]) recentlyWritten(ctx context.Context, id K) bool {
+	session, ok := ctx.Value(sessionKey).(string)
+	if !ok {
+		return false
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	write := sessionWrite[K]{session: session, id: id}
+	at, written := s.writes[write]
+	if written && s.now().Sub(at) >= s.Window {
+		delete(s.writes, write)
+		return false
+	}
+	return written
+}
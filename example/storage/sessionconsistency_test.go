@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSessionConsistency(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should read own write in session despite lagging replica", func(t *testing.T) {
+		// Writes go to primary, reads to a lagging replica unless fresh.
+		primary, replica := User{ID: "10", Name: "old"}, User{ID: "10", Name: "old"}
+		backend := &stubRepository{
+			getFunc: func(ctx context.Context, id UserID) (User, error) {
+				if isFreshRead(ctx) {
+					return primary, nil
+				}
+				return replica, nil
+			},
+			setFunc: func(ctx context.Context, entity User) error {
+				primary = entity
+				return nil
+			},
+		}
+		cache := &Cache[User, UserID]{Next: backend, cached: make(map[UserID]User)}
+		repo := NewSessionConsistency[User, UserID](cache, time.Minute)
+		writer, reader := ContextWithSession(ctx, "writer"), ContextWithSession(ctx, "reader")
+
+		if err := repo.Set(writer, User{ID: "10", Name: "new"}); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if user, _ := repo.Get(reader, "10"); user.Name != "old" {
+			t.Errorf("Got %s but expected other session to read stale entity", user.Name)
+		}
+		if user, _ := repo.Get(writer, "10"); user.Name != "new" {
+			t.Errorf("Got %s but expected writer to read own write", user.Name)
+		}
+	})
+	t.Run("Should stop forcing fresh reads after window", func(t *testing.T) {
+		var fresh bool
+		next := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			fresh = isFreshRead(ctx)
+			return User{ID: id}, nil
+		}}
+		now := time.Now()
+		repo := NewSessionConsistency[User, UserID](next, time.Second)
+		repo.now = func() time.Time {
+			return now
+		}
+		session := ContextWithSession(ctx, "s1")
+		_ = repo.Delete(session, "10")
+
+		if _, _ = repo.Get(session, "10"); !fresh {
+			t.Error("Expected fresh read within window")
+		}
+		if _, _ = repo.Get(session, "11"); fresh {
+			t.Error("Expected regular read of entity not written")
+		}
+		if _, _ = repo.Get(ctx, "10"); fresh {
+			t.Error("Expected regular read without session")
+		}
+		now = now.Add(time.Second)
+		if _, _ = repo.Get(session, "10"); fresh {
+			t.Error("Expected regular read after window")
+		}
+		if len(repo.writes) != 0 {
+			t.Errorf("Got %d tracked writes but expected expired one dropped", len(repo.writes))
+		}
+	})
+	t.Run("Should drop expired writes never read", func(t *testing.T) {
+		now := time.Now()
+		repo := NewSessionConsistency[User, UserID](&stubRepository{}, time.Second)
+		repo.now = func() time.Time {
+			return now
+		}
+		for i := 0; i < 1000; i++ {
+			_ = repo.Set(ContextWithSession(ctx, fmt.Sprint(i)), User{ID: "10"})
+			now = now.Add(100 * time.Millisecond)
+		}
+		if len(repo.writes) > 2*minSweepAt {
+			t.Errorf("Got %d tracked writes but expected at most %d", len(repo.writes), 2*minSweepAt)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ShadowRead returns results of Next while asynchronously comparing them with reads of Secondary,
+// e.g. to validate a new backend before switching to it. Shadow reads don't affect returned values or latency.
+// At most maxInFlight shadow reads run at once, see NewShadowRead; Gets over it aren't shadowed.
+type ShadowRead[T Entity[K], K Identifier] struct {
+	Next      Repository[T, K]
+	Secondary Repository[T, K]
+	// Equal compares entities read from both repositories. Defaults to reflect.DeepEqual.
+	Equal func(primary, secondary T) bool
+	// Timeout bounds each shadow read, a timed out read is reported as mismatch. NewShadowRead sets it to a second; zero means no limit.
+	Timeout time.Duration
+	// OnMismatch is called with results of both repositories when they differ. Gets aren't shadowed when nil.
+	OnMismatch func(id K, primary T, primaryErr error, secondary T, secondaryErr error)
+	slots      chan struct{}
+}
+
+func NewShadowRead[T Entity[K], K Identifier](next, secondary Repository[T, K], maxInFlight int) *ShadowRead[T, K] {
+	return &ShadowRead[T, K]{
+		Next:      next,
+		Secondary: secondary,
+		Timeout:   time.Second,
+		slots:     make(chan struct{}, maxInFlight),
+	}
+}
+
+func (s *ShadowRead[T, K]) Get(ctx context.Context, id K) (T, error) {
+	entity, err := s.Next.Get(ctx, id)
+	if s.OnMismatch == nil {
+		return entity, err
+	}
+	select {
+	case s.slots <- struct{}{}:
+		go s.shadow(context.WithoutCancel(ctx), id, entity, err)
+	default:
+	}
+	return entity, err
+}
+
+func (s *ShadowRead[T, K]) Set(ctx context.Context, entity T) error {
+	return s.Next.Set(ctx, entity)
+}
+
+func (s *ShadowRead[T, K]) Delete(ctx context.Context, id K) error {
+	return s.Next.Delete(ctx, id)
+}
+
+func (s *ShadowRead[T, K]) shadow(ctx context.Context, id K, primary T, primaryErr error) {
+	defer func() {
+		<-s.slots
+	}()
+	if s.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.Timeout)
+		defer cancel()
+	}
+	secondary, secondaryErr := s.Secondary.Get(ctx, id)
+	if primaryErr != nil || secondaryErr != nil {
+		// Only missing entity is an expected result, any other error differs from a read of the other repository.
+		if !errors.Is(primaryErr, errNotFound) || !errors.Is(secondaryErr, errNotFound) {
+			s.OnMismatch(id, primary, primaryErr, secondary, secondaryErr)
+		}
+		return
+	}
+	equal := s.Equal
+	if equal == nil {
+		equal = func(primary, secondary T) bool {
+			return reflect.DeepEqual(primary, secondary)
+		}
+	}
+	if !equal(primary, secondary) {
+		s.OnMismatch(id, primary, primaryErr, secondary, secondaryErr)
+	}
+}
@@ -0,0 +1,131 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestShadowRead_Get(t *testing.T) {
+	ctx := context.Background()
+	type mismatch struct {
+		id                 UserID
+		primary, secondary User
+	}
+	primary := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+		return User{ID: id, Name: "John"}, nil
+	}}
+	newRepo := func(secondary Repository[User, UserID]) (*ShadowRead[User, UserID], chan mismatch) {
+		mismatches := make(chan mismatch, 1)
+		repo := NewShadowRead[User, UserID](primary, secondary, 1)
+		repo.OnMismatch = func(id UserID, primary User, primaryErr error, secondary User, secondaryErr error) {
+			mismatches <- mismatch{id: id, primary: primary, secondary: secondary}
+		}
+		return repo, mismatches
+	}
+
+	t.Run("Should return primary result and report mismatch", func(t *testing.T) {
+		release := make(chan struct{})
+		repo, mismatches := newRepo(&stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			<-release
+			return User{ID: id, Name: "Jane"}, nil
+		}})
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "John" {
+			t.Errorf("Got %v, %v but expected primary entity", user, err)
+		}
+		close(release)
+		select {
+		case got := <-mismatches:
+			if got.id != "10" || got.primary.Name != "John" || got.secondary.Name != "Jane" {
+				t.Errorf("Got %+v but expected mismatch of John and Jane", got)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected mismatch to be reported")
+		}
+	})
+	t.Run("Should report secondary missing entity", func(t *testing.T) {
+		repo, mismatches := newRepo(&stubRepository{})
+		_, _ = repo.Get(ctx, "10")
+		select {
+		case <-mismatches:
+		case <-time.After(time.Second):
+			t.Fatal("Expected mismatch to be reported")
+		}
+	})
+	t.Run("Should not report matching reads", func(t *testing.T) {
+		secondary := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{ID: id, Name: "John"}, nil
+		}}
+		repo, mismatches := newRepo(secondary)
+		_, _ = repo.Get(ctx, "10")
+		for secondary.count("Get") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		select {
+		case got := <-mismatches:
+			t.Errorf("Got unexpected mismatch %+v", got)
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+	t.Run("Should not report entity missing in both", func(t *testing.T) {
+		primary := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			return User{}, fmt.Errorf("primary: %w", errNotFound)
+		}}
+		secondary := &stubRepository{}
+		repo := NewShadowRead[User, UserID](primary, secondary, 1)
+		mismatches := make(chan struct{}, 1)
+		repo.OnMismatch = func(id UserID, primary User, primaryErr error, secondary User, secondaryErr error) {
+			mismatches <- struct{}{}
+		}
+		_, _ = repo.Get(ctx, "10")
+		for secondary.count("Get") == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		select {
+		case <-mismatches:
+			t.Error("Got unexpected mismatch")
+		case <-time.After(20 * time.Millisecond):
+		}
+	})
+	t.Run("Should report timed out shadow read", func(t *testing.T) {
+		repo, mismatches := newRepo(&stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			<-ctx.Done()
+			return User{}, ctx.Err()
+		}})
+		repo.Timeout = time.Millisecond
+		_, _ = repo.Get(ctx, "10")
+		select {
+		case <-mismatches:
+		case <-time.After(time.Second):
+			t.Fatal("Expected mismatch to be reported")
+		}
+	})
+	t.Run("Should skip shadow reads over limit", func(t *testing.T) {
+		release := make(chan struct{})
+		secondary := &stubRepository{getFunc: func(ctx context.Context, id UserID) (User, error) {
+			<-release
+			return User{ID: id, Name: "John"}, nil
+		}}
+		repo, _ := newRepo(secondary)
+		_, _ = repo.Get(ctx, "10")
+		_, _ = repo.Get(ctx, "11")
+		close(release)
+		for len(repo.slots) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if calls := secondary.count("Get"); calls != 1 {
+			t.Errorf("Got %d shadow Gets but expected 1", calls)
+		}
+	})
+	t.Run("Should not shadow without mismatch callback", func(t *testing.T) {
+		secondary := &stubRepository{}
+		repo := NewShadowRead[User, UserID](primary, secondary, 1)
+		if _, err := repo.Get(ctx, "10"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+		if calls := secondary.count("Get"); calls != 0 {
+			t.Errorf("Got %d shadow Gets but expected none", calls)
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+// Sharded routes entities to one of shards by hash of serialized identifier.
+// Shard is chosen with jump consistent hash, so the mapping is deterministic for a fixed number of shards.
+type Sharded[T Entity[K], K Identifier] struct {
+	shards               []Repository[T, K]
+	identifierSerializer serializer[K]
+	hash                 func([]byte) uint64
+}
+
+var errNoShards = errors.New("no shards")
+
+// NewSharded creates repository routing to shards. Hash defaults to FNV-1a when nil.
+func NewSharded[T Entity[K], K Identifier](shards []Repository[T, K], identitySerializer serializer[K], hash func([]byte) uint64) (*Sharded[T, K], error) {
+	if len(shards) == 0 {
+		return nil, errNoShards
+	}
+	if hash == nil {
+		hash = fnvHash
+	}
+	return &Sharded[T, K]{
+		shards:               shards,
+		identifierSerializer: identitySerializer,
+		hash:                 hash,
+	}, nil
+}
+
+func (s *Sharded[T, K]) Get(ctx context.Context, id K) (T, error) {
+	shard, err := s.shard(id)
+	if err != nil {
+		var entity T
+		return entity, err
+	}
+	return shard.Get(ctx, id)
+}
+
+func (s *Sharded[T, K]) Set(ctx context.Context, entity T) error {
+	shard, err := s.shard(entity.Identifier())
+	if err != nil {
+		return err
+	}
+	return shard.Set(ctx, entity)
+}
+
+func (s *Sharded[T, K]) Delete(ctx context.Context, id K) error {
+	shard, err := s.shard(id)
+	if err != nil {
+		return err
+	}
+	return shard.Delete(ctx, id)
+}
+
+func (s *Sharded[T, K]) shard(id K) (Repository[T, K], error) {
+	key, err := s.identifierSerializer.Serialize(id)
+	if err != nil {
+		return nil, fmt.Errorf("%w identifier: %w", ErrSerialize, err)
+	}
+	return s.shards[jumpHash(s.hash(key), len(s.shards))], nil
+}
+
+func fnvHash(key []byte) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(key)
+	return h.Sum64()
+}
+
+// jumpHash maps key to one of n buckets (Lamping, Veach: "A Fast, Minimal Memory, Consistent Hash Algorithm").
+func jumpHash(key uint64, n int) int {
+	var b, j int64 = -1, 0
+	for j < int64(n) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
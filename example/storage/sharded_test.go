@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestSharded(t *testing.T) {
+	newShards := func() []Repository[User, UserID] {
+		shards := make([]Repository[User, UserID], 4)
+		for i := range shards {
+			shards[i] = NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+		}
+		return shards
+	}
+
+	t.Run("Should route identifier to the same shard", func(t *testing.T) {
+		shards := newShards()
+		repo, err := NewSharded[User, UserID](shards, userIDSerializer{}, nil)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		ctx := context.Background()
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		owner, _ := repo.shard("10")
+		for i := 0; i < 10; i++ {
+			if shard, _ := repo.shard("10"); shard != owner {
+				t.Fatal("Expected identifier routed to the same shard")
+			}
+		}
+		if _, err := owner.Get(ctx, "10"); err != nil {
+			t.Errorf("Expected entity stored in owning shard but got: %v", err)
+		}
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "John" {
+			t.Errorf("Expected entity read from owning shard but got: %v, %v", user, err)
+		}
+	})
+	t.Run("Should distribute identifiers across shards", func(t *testing.T) {
+		shards := newShards()
+		repo, _ := NewSharded[User, UserID](shards, userIDSerializer{}, nil)
+		counts := make(map[Repository[User, UserID]]int)
+		const entities = 1000
+		for i := 0; i < entities; i++ {
+			shard, _ := repo.shard(UserID(fmt.Sprintf("user-%d", i)))
+			counts[shard]++
+		}
+		for i, shard := range shards {
+			if counts[shard] < entities/len(shards)/2 {
+				t.Errorf("Got %d entities in shard %d, expected roughly %d", counts[shard], i, entities/len(shards))
+			}
+		}
+	})
+	t.Run("Should return error without shards", func(t *testing.T) {
+		if _, err := NewSharded[User, UserID](nil, userIDSerializer{}, nil); err == nil {
+			t.Error("Expected error about missing shards but got nil")
+		}
+	})
+}
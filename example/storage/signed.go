@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+)
+
+// SignedSerializer signs serialized entities with HMAC-SHA256 of Key and verifies them when unserializing.
+// Keys can be rotated by moving the previous Key to VerificationKeys, which are accepted but never used to sign.
+type SignedSerializer[T any] struct {
+	Next             serializer[T]
+	Key              []byte
+	VerificationKeys [][]byte
+}
+
+// ErrSignatureInvalid is returned for entities without a valid signature.
+var ErrSignatureInvalid = errors.New("invalid entity signature")
+
+func (s SignedSerializer[T]) Serialize(entity T) ([]byte, error) {
+	raw, err := s.Next.Serialize(entity)
+	if err != nil {
+		return nil, err
+	}
+	return append(raw, sign(s.Key, raw)...), nil
+}
+
+func (s SignedSerializer[T]) UnSerialize(raw []byte) (T, error) {
+	var entity T
+	if len(raw) < sha256.Size {
+		return entity, ErrSignatureInvalid
+	}
+	payload, signature := raw[:len(raw)-sha256.Size], raw[len(raw)-sha256.Size:]
+	for _, key := range append([][]byte{s.Key}, s.VerificationKeys...) {
+		if hmac.Equal(signature, sign(key, payload)) {
+			return s.Next.UnSerialize(payload)
+		}
+	}
+	return entity, ErrSignatureInvalid
+}
+
+func sign(key, payload []byte) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+	return mac.Sum(nil)
+}
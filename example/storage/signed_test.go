@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSignedSerializer(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should read entity with valid signature", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, SignedSerializer[User]{Next: userSerializer{}, Key: []byte("key")})
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "John" {
+			t.Errorf("Got %v, %v but expected entity", user, err)
+		}
+	})
+	t.Run("Should reject tampered entity", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, SignedSerializer[User]{Next: userSerializer{}, Key: []byte("key")})
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		repo.entities["10"][len(`{"ID":"10","Name":"`)] = 'j'
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, ErrSignatureInvalid) || !errors.Is(err, ErrDeserialize) {
+			t.Errorf("Expected invalid signature error but got: %v", err)
+		}
+	})
+	t.Run("Should accept entities signed with rotated key", func(t *testing.T) {
+		repo := NewInMemoryRepository[User, UserID](userIDSerializer{}, SignedSerializer[User]{Next: userSerializer{}, Key: []byte("old")})
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		repo.entitySerializer = SignedSerializer[User]{Next: userSerializer{}, Key: []byte("new"), VerificationKeys: [][]byte{[]byte("old")}}
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "John" {
+			t.Errorf("Got %v, %v but expected entity signed with old key", user, err)
+		}
+		repo.entitySerializer = SignedSerializer[User]{Next: userSerializer{}, Key: []byte("new")}
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, ErrSignatureInvalid) {
+			t.Errorf("Expected invalid signature error after dropping old key but got: %v", err)
+		}
+	})
+}
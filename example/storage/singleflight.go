@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"github.com/jlisicki/middlewarebuilder"
+)
+
+// SingleFlightGet performs a single downstream Get for concurrent Gets of an entity, sharing its result.
+type SingleFlightGet[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+	// Key maps identifier to key of shared Gets, it must be unique for distinct identifiers, e.g. for pointer identifiers.
+	// Defaults to Go-syntax representation of identifier.
+	Key     func(K) string
+	flights middlewarebuilder.SingleFlight[T]
+}
+
+// NewSingleFlightGetFactory creates factory of SingleFlightGet middlewares.
+func NewSingleFlightGetFactory[T Entity[K], K Identifier]() middlewarebuilder.Factory[Repository[T, K]] {
+	return middlewarebuilder.Named[Repository[T, K]]("SingleFlightGet", middlewarebuilder.FactoryFunc[Repository[T, K]](func(next Repository[T, K]) (Repository[T, K], error) {
+		return &SingleFlightGet[T, K]{Next: next}, nil
+	}))
+}
+
+// key of Get of id.
+func (s *SingleFlightGet[T, K]) key(id K) string {
+	if s.Key != nil {
+		return s.Key(id)
+	}
+	return fmt.Sprintf("%#v", id)
+}
+
+// Get shares entity with concurrent callers, so it must not be mutated in place.
+// The downstream Get isn't cancelled with context of any caller, but keeps deadline of the first one.
+// Each caller stops waiting when its own context is done.
+func (s *SingleFlightGet[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return s.flights.DoContext(ctx, s.key(id), func(ctx context.Context) (T, error) {
+		return s.Next.Get(ctx, id)
+	})
+}
+
+func (s *SingleFlightGet[T, K]) Set(ctx context.Context, entity T) error {
+	return s.Next.Set(ctx, entity)
+}
+
+func (s *SingleFlightGet[T, K]) Delete(ctx context.Context, id K) error {
+	return s.Next.Delete(ctx, id)
+}
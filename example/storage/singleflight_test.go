@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+)
+
+func TestSingleFlightGet(t *testing.T) {
+	newRepo := func(getFunc func(ctx context.Context, id UserID) (User, error)) (*SingleFlightGet[User, UserID], *stubRepository) {
+		next := &stubRepository{getFunc: getFunc}
+		repo, _ := NewSingleFlightGetFactory[User, UserID]().Create(next)
+		return repo.(*SingleFlightGet[User, UserID]), next
+	}
+
+	t.Run("Should perform single downstream Get for concurrent Gets", func(t *testing.T) {
+		const readers = 10
+		release := make(chan struct{})
+		repo, next := newRepo(func(ctx context.Context, id UserID) (User, error) {
+			<-release
+			return User{ID: id, Name: "John"}, nil
+		})
+
+		var wg sync.WaitGroup
+		for i := 0; i < readers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if user, err := repo.Get(context.Background(), "10"); err != nil || user.Name != "John" {
+					t.Errorf("Got %v, %v but expected shared entity", user, err)
+				}
+			}()
+		}
+		for repo.flights.Callers(repo.key("10")) != readers {
+			runtime.Gosched()
+		}
+		close(release)
+		wg.Wait()
+
+		if calls := next.count("Get"); calls != 1 {
+			t.Errorf("Got %d downstream Gets but expected 1", calls)
+		}
+	})
+	t.Run("Should complete shared Get when first reader cancels", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		repo, _ := newRepo(func(ctx context.Context, id UserID) (User, error) {
+			close(started)
+			<-release
+			return User{ID: id, Name: "John"}, ctx.Err()
+		})
+		first, cancel := context.WithCancel(context.Background())
+		firstErr := make(chan error)
+		go func() {
+			_, err := repo.Get(first, "10")
+			firstErr <- err
+		}()
+		<-started
+		shared := make(chan error)
+		go func() {
+			_, err := repo.Get(context.Background(), "10")
+			shared <- err
+		}()
+		for repo.flights.Callers(repo.key("10")) != 2 {
+			runtime.Gosched()
+		}
+		cancel()
+		if err := <-firstErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context cancelled error but got: %v", err)
+		}
+		close(release)
+		if err := <-shared; err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should share Gets by custom key", func(t *testing.T) {
+		repo, _ := newRepo(nil)
+		repo.Key = func(id UserID) string {
+			return "user"
+		}
+		if repo.key("10") != repo.key("11") {
+			t.Error("Expected identifiers to share key")
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogTelemetry logs operations with their latency as structured records, labeled with a latency bucket.
+// Records are logged with operation context, so handlers can correlate them with traces,
+// and include request identifier set with ContextWithRequestID.
+type SlogTelemetry[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+	// Logger of records. Defaults to slog.Default.
+	Logger *slog.Logger
+	Level  slog.Level
+	// Buckets are ascending upper bounds of latency buckets. Defaults to DefaultLatencyBuckets.
+	Buckets []time.Duration
+	// Now returns current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+type requestIDCtxKey string
+
+var requestIDKey requestIDCtxKey = "requestID"
+
+// DefaultLatencyBuckets of SlogTelemetry.
+var DefaultLatencyBuckets = []time.Duration{time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond, time.Second}
+
+// ContextWithRequestID sets identifier of request operations in context are made for.
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+func (s SlogTelemetry[T, K]) Get(ctx context.Context, id K) (T, error) {
+	sT := s.now()
+	entity, err := s.Next.Get(ctx, id)
+	s.log(ctx, "Get", sT, err)
+	return entity, err
+}
+
+func (s SlogTelemetry[T, K]) Set(ctx context.Context, entity T) error {
+	sT := s.now()
+	err := s.Next.Set(ctx, entity)
+	s.log(ctx, "Set", sT, err)
+	return err
+}
+
+func (s SlogTelemetry[T, K]) Delete(ctx context.Context, id K) error {
+	sT := s.now()
+	err := s.Next.Delete(ctx, id)
+	s.log(ctx, "Delete", sT, err)
+	return err
+}
+
+func (s SlogTelemetry[T, K]) log(ctx context.Context, op string, sT time.Time, err error) {
+	logger := s.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if !logger.Enabled(ctx, s.Level) {
+		return
+	}
+	latency := s.now().Sub(sT)
+	attrs := []slog.Attr{
+		slog.String("op", op),
+		slog.Float64("latency_ms", float64(latency)/float64(time.Millisecond)),
+		slog.String("bucket", s.bucket(latency)),
+	}
+	if requestID, ok := ctx.Value(requestIDKey).(string); ok {
+		attrs = append(attrs, slog.String("request_id", requestID))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	logger.LogAttrs(ctx, s.Level, "repository operation", attrs...)
+}
+
+// bucket labels latency with the first bucket it fits in.
+func (s SlogTelemetry[T, K]) bucket(latency time.Duration) string {
+	buckets := s.Buckets
+	if buckets == nil {
+		buckets = DefaultLatencyBuckets
+	}
+	for _, bound := range buckets {
+		if latency < bound {
+			return "<" + bound.String()
+		}
+	}
+	if len(buckets) == 0 {
+		return "all"
+	}
+	return ">=" + buckets[len(buckets)-1].String()
+}
+
+func (s SlogTelemetry[T, K]) now() time.Time {
+	if s.Now == nil {
+		return time.Now()
+	}
+	return s.Now()
+}
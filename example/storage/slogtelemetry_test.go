@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSlogTelemetry(t *testing.T) {
+	ctx := context.Background()
+	newRepo := func(out *bytes.Buffer, latency time.Duration) SlogTelemetry[User, UserID] {
+		now := time.Now()
+		return SlogTelemetry[User, UserID]{
+			Next:   &stubRepository{},
+			Logger: slog.New(slog.NewJSONHandler(out, nil)),
+			Level:  slog.LevelInfo,
+			Now: func() time.Time {
+				now = now.Add(latency)
+				return now
+			},
+		}
+	}
+
+	t.Run("Should log structured records with latency bucket and request id", func(t *testing.T) {
+		var out bytes.Buffer
+		repo := newRepo(&out, 5*time.Millisecond)
+		_ = repo.Set(ContextWithRequestID(ctx, "req-1"), User{ID: "10"})
+		_, _ = repo.Get(ctx, "10")
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("Got %d records but expected 2", len(lines))
+		}
+		var set, get map[string]any
+		_ = json.Unmarshal([]byte(lines[0]), &set)
+		_ = json.Unmarshal([]byte(lines[1]), &get)
+		expected := map[string]any{"op": "Set", "latency_ms": 5.0, "bucket": "<10ms", "request_id": "req-1", "level": "INFO"}
+		for key, value := range expected {
+			if set[key] != value {
+				t.Errorf("Got %s %v but expected %v", key, set[key], value)
+			}
+		}
+		if _, exists := get["request_id"]; exists {
+			t.Errorf("Expected no request id without one in context but got %v", get["request_id"])
+		}
+		if get["error"] != errNotFound.Error() {
+			t.Errorf("Got error %v but expected %v", get["error"], errNotFound)
+		}
+	})
+	t.Run("Should label latency over all buckets", func(t *testing.T) {
+		var out bytes.Buffer
+		repo := newRepo(&out, 2*time.Second)
+		_ = repo.Delete(ctx, "10")
+		var record map[string]any
+		_ = json.Unmarshal(out.Bytes(), &record)
+		if record["bucket"] != ">=1s" {
+			t.Errorf("Got bucket %v but expected >=1s", record["bucket"])
+		}
+	})
+	t.Run("Should skip records below logger level", func(t *testing.T) {
+		var out bytes.Buffer
+		repo := newRepo(&out, time.Millisecond)
+		repo.Level = slog.LevelDebug
+		_ = repo.Delete(ctx, "10")
+		if out.Len() != 0 {
+			t.Errorf("Got '%s' but expected no records", out.String())
+		}
+	})
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// SlowLog reports operations taking longer than Threshold to OnSlow.
+type SlowLog[T Entity[K], K Identifier] struct {
+	Next      Repository[T, K]
+	Threshold time.Duration
+	OnSlow    func(op string, d time.Duration, key K)
+}
+
+func (s SlowLog[T, K]) Get(ctx context.Context, id K) (T, error) {
+	defer s.observe("Get", id, time.Now())
+	return s.Next.Get(ctx, id)
+}
+
+func (s SlowLog[T, K]) Set(ctx context.Context, entity T) error {
+	defer s.observe("Set", entity.Identifier(), time.Now())
+	return s.Next.Set(ctx, entity)
+}
+
+func (s SlowLog[T, K]) Delete(ctx context.Context, id K) error {
+	defer s.observe("Delete", id, time.Now())
+	return s.Next.Delete(ctx, id)
+}
+
+func (s SlowLog[T, K]) observe(op string, key K, sT time.Time) {
+	if d := time.Since(sT); d > s.Threshold {
+		s.OnSlow(op, d, key)
+	}
+}
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSlowLog(t *testing.T) {
+	ctx := context.Background()
+	type slowOp struct {
+		op  string
+		d   time.Duration
+		key UserID
+	}
+	var reported []slowOp
+	onSlow := func(op string, d time.Duration, key UserID) {
+		reported = append(reported, slowOp{op: op, d: d, key: key})
+	}
+
+	t.Run("Should report slow operation", func(t *testing.T) {
+		reported = nil
+		next := &stubRepository{setFunc: func(ctx context.Context, entity User) error {
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		}}
+		repo := SlowLog[User, UserID]{Next: next, Threshold: 20 * time.Millisecond, OnSlow: onSlow}
+		_ = repo.Set(ctx, User{ID: "10"})
+		if len(reported) != 1 {
+			t.Fatalf("Got %d reports but expected 1", len(reported))
+		}
+		if got := reported[0]; got.op != "Set" || got.key != "10" || got.d < 30*time.Millisecond {
+			t.Errorf("Got %+v but expected slow Set of 10", got)
+		}
+	})
+	t.Run("Should not report fast operations", func(t *testing.T) {
+		reported = nil
+		repo := SlowLog[User, UserID]{Next: &stubRepository{}, Threshold: 20 * time.Millisecond, OnSlow: onSlow}
+		_, _ = repo.Get(ctx, "10")
+		_ = repo.Set(ctx, User{ID: "10"})
+		_ = repo.Delete(ctx, "10")
+		if len(reported) != 0 {
+			t.Errorf("Got %v but expected no reports", reported)
+		}
+	})
+}
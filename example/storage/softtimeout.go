@@ -0,0 +1,38 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// SoftTimeout bounds operations with Timeout unless the context already has an earlier deadline,
+// in which case the context is passed unchanged so outer budgets are never shortened.
+type SoftTimeout[T Entity[K], K Identifier] struct {
+	Next    Repository[T, K]
+	Timeout time.Duration
+}
+
+func (s SoftTimeout[T, K]) Get(ctx context.Context, id K) (T, error) {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	return s.Next.Get(ctx, id)
+}
+
+func (s SoftTimeout[T, K]) Set(ctx context.Context, entity T) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	return s.Next.Set(ctx, entity)
+}
+
+func (s SoftTimeout[T, K]) Delete(ctx context.Context, id K) error {
+	ctx, cancel := s.withTimeout(ctx)
+	defer cancel()
+	return s.Next.Delete(ctx, id)
+}
+
+func (s SoftTimeout[T, K]) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if deadline, ok := ctx.Deadline(); ok && !deadline.After(time.Now().Add(s.Timeout)) {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, s.Timeout)
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSoftTimeout(t *testing.T) {
+	var got context.Context
+	next := &stubRepository{
+		getFunc: func(ctx context.Context, id UserID) (User, error) {
+			got = ctx
+			return User{}, nil
+		},
+	}
+	repo := SoftTimeout[User, UserID]{Next: next, Timeout: time.Second}
+
+	t.Run("Should apply timeout when context has no deadline", func(t *testing.T) {
+		sT := time.Now()
+		_, _ = repo.Get(context.Background(), "10")
+		deadline, ok := got.Deadline()
+		if !ok {
+			t.Fatal("Expected deadline to be set")
+		}
+		if timeout := deadline.Sub(sT); timeout < time.Second || timeout > 2*time.Second {
+			t.Errorf("Got timeout %s but expected 1s", timeout)
+		}
+	})
+	t.Run("Should pass context unchanged when outer deadline is tighter", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*100)
+		defer cancel()
+		_, _ = repo.Get(ctx, "10")
+		if got != ctx {
+			t.Error("Expected context to be passed unchanged")
+		}
+	})
+	t.Run("Should apply timeout when outer deadline is later", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		sT := time.Now()
+		_, _ = repo.Get(ctx, "10")
+		deadline, _ := got.Deadline()
+		if timeout := deadline.Sub(sT); timeout > 2*time.Second {
+			t.Errorf("Got timeout %s but expected 1s", timeout)
+		}
+	})
+}
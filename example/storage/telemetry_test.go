@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"strings"
+	"testing"
+	"time"
+)
+
+func captureLog(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	output, flags := log.Writer(), log.Flags()
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	t.Cleanup(func() {
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	})
+	return &buf
+}
+
+func TestTelemetry(t *testing.T) {
+	ctx := context.Background()
+	t.Run("Should omit key by default", func(t *testing.T) {
+		buf := captureLog(t)
+		repo := Telemetry[User, UserID]{Next: &stubRepository{}}
+		_, _ = repo.Get(ctx, "10")
+		if out := buf.String(); !strings.HasPrefix(out, "Get: ") || strings.Contains(out, "10") {
+			t.Errorf("Expected log without key but got '%s'", out)
+		}
+	})
+	t.Run("Should label operation with key bucket", func(t *testing.T) {
+		buf := captureLog(t)
+		repo := Telemetry[User, UserID]{
+			Next: &stubRepository{},
+			KeyBucketer: func(id UserID) string {
+				return "bucket-" + string(id[0])
+			},
+		}
+		_, _ = repo.Get(ctx, "10")
+		_ = repo.Set(ctx, User{ID: "20"})
+		_ = repo.Delete(ctx, "30")
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		expected := []string{"Get[bucket-1]: ", "Set[bucket-2]: ", "Delete[bucket-3]: "}
+		if len(lines) != len(expected) {
+			t.Fatalf("Got %d log lines but expected %d", len(lines), len(expected))
+		}
+		for i, line := range lines {
+			if !strings.HasPrefix(line, expected[i]) {
+				t.Errorf("Got '%s' but expected prefix '%s'", line, expected[i])
+			}
+		}
+	})
+	t.Run("Should measure duration with injected clock", func(t *testing.T) {
+		buf := captureLog(t)
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		repo := Telemetry[User, UserID]{
+			Next: &stubRepository{},
+			Now: func() time.Time {
+				now = now.Add(5 * time.Second)
+				return now
+			},
+		}
+		_ = repo.Delete(ctx, "10")
+		if out := buf.String(); out != "Delete: 5s\n" {
+			t.Errorf("Got '%s' but expected 'Delete: 5s'", out)
+		}
+	})
+	t.Run("Should log to injected logger", func(t *testing.T) {
+		var buf bytes.Buffer
+		repo := Telemetry[User, UserID]{Next: &stubRepository{}, Logger: log.New(&buf, "[telemetry] ", 0)}
+		_ = repo.Set(ctx, User{ID: "10"})
+		if out := buf.String(); !strings.HasPrefix(out, "[telemetry] Set: ") {
+			t.Errorf("Got '%s' but expected line of injected logger", out)
+		}
+	})
+}
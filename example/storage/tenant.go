@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+)
+
+// TenantScope isolates tenants by validating that identifiers are within the scope of tenant read from context,
+// e.g. prefixed with tenant. All methods take scoped identifiers, so entities read can be written back unchanged.
+// Entities outside of the tenant's scope are reported as not found.
+type TenantScope[T Entity[K], K Identifier] struct {
+	Next Repository[T, K]
+	// Owns reports whether scoped identifier belongs to tenant.
+	Owns func(tenant string, id K) bool
+}
+
+type tenantCtxKey string
+
+var tenantKey tenantCtxKey = "tenant"
+
+var (
+	// ErrMissingTenant is returned when context has no tenant.
+	ErrMissingTenant = errors.New("missing tenant")
+	// ErrTenantMismatch is returned when writing entity outside of tenant's scope.
+	ErrTenantMismatch = errors.New("entity belongs to another tenant")
+)
+
+// ContextWithTenant sets tenant used by tenant-aware middlewares.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey, tenant)
+}
+
+func tenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey).(string)
+	return tenant, ok
+}
+
+func (s TenantScope[T, K]) Get(ctx context.Context, id K) (T, error) {
+	var zero T
+	tenant, ok := tenantFromContext(ctx)
+	if !ok {
+		return zero, ErrMissingTenant
+	}
+	if !s.Owns(tenant, id) {
+		return zero, errNotFound
+	}
+	return s.Next.Get(ctx, id)
+}
+
+func (s TenantScope[T, K]) Set(ctx context.Context, entity T) error {
+	tenant, ok := tenantFromContext(ctx)
+	if !ok {
+		return ErrMissingTenant
+	}
+	if !s.Owns(tenant, entity.Identifier()) {
+		return ErrTenantMismatch
+	}
+	return s.Next.Set(ctx, entity)
+}
+
+func (s TenantScope[T, K]) Delete(ctx context.Context, id K) error {
+	tenant, ok := tenantFromContext(ctx)
+	if !ok {
+		return ErrMissingTenant
+	}
+	if !s.Owns(tenant, id) {
+		return errNotFound
+	}
+	return s.Next.Delete(ctx, id)
+}
@@ -0,0 +1,54 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTenantScope(t *testing.T) {
+	storage := NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})
+	repo := TenantScope[User, UserID]{
+		Next: storage,
+		Owns: func(tenant string, id UserID) bool {
+			return strings.HasPrefix(string(id), tenant+":")
+		},
+	}
+	tenantA := ContextWithTenant(context.Background(), "a")
+	tenantB := ContextWithTenant(context.Background(), "b")
+	_ = repo.Set(tenantA, User{ID: "a:10", Name: "John"})
+
+	t.Run("Should read tenant's entity", func(t *testing.T) {
+		user, err := repo.Get(tenantA, "a:10")
+		if err != nil || user.Name != "John" {
+			t.Errorf("Got %v, %v but expected John", user, err)
+		}
+		if err := repo.Set(tenantA, user); err != nil {
+			t.Errorf("Expected entity read to be writable back but got: %v", err)
+		}
+	})
+	t.Run("Should return not found for another tenant", func(t *testing.T) {
+		if _, err := repo.Get(tenantB, "a:10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+	})
+	t.Run("Should reject writes outside of tenant's scope", func(t *testing.T) {
+		if err := repo.Set(tenantB, User{ID: "a:11"}); !errors.Is(err, ErrTenantMismatch) {
+			t.Errorf("Expected tenant mismatch error but got: %v", err)
+		}
+	})
+	t.Run("Should not delete another tenant's entity", func(t *testing.T) {
+		if err := repo.Delete(tenantB, "a:10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+		if _, err := storage.Get(tenantA, "a:10"); err != nil {
+			t.Errorf("Expected entity kept but got: %v", err)
+		}
+	})
+	t.Run("Should return error without tenant", func(t *testing.T) {
+		if _, err := repo.Get(context.Background(), "a:10"); !errors.Is(err, ErrMissingTenant) {
+			t.Errorf("Expected missing tenant error but got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type (
+	// Stamper is implemented by entities with creation and update times.
+	// Setters return a stamped copy, so they work for entities passed by value.
+	Stamper[T any] interface {
+		SetCreatedAt(t time.Time) T
+		SetUpdatedAt(t time.Time) T
+	}
+	StampedEntity[T any, K Identifier] interface {
+		Entity[K]
+		Stamper[T]
+	}
+
+	// Timestamps stamps entities on Set. Creation time is set only for entities not yet stored,
+	// so an updated entity is expected to carry its original creation time.
+	Timestamps[T StampedEntity[T, K], K Identifier] struct {
+		Next Repository[T, K]
+		// Now returns current time. Defaults to time.Now.
+		Now func() time.Time
+	}
+)
+
+func (t Timestamps[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return t.Next.Get(ctx, id)
+}
+
+func (t Timestamps[T, K]) Set(ctx context.Context, entity T) error {
+	now := t.now()
+	_, err := t.Next.Get(ctx, entity.Identifier())
+	switch {
+	case errors.Is(err, errNotFound):
+		entity = entity.SetCreatedAt(now)
+	case err != nil:
+		return err
+	}
+	return t.Next.Set(ctx, entity.SetUpdatedAt(now))
+}
+
+func (t Timestamps[T, K]) Delete(ctx context.Context, id K) error {
+	return t.Next.Delete(ctx, id)
+}
+
+func (t Timestamps[T, K]) now() time.Time {
+	if t.Now == nil {
+		return time.Now()
+	}
+	return t.Now()
+}
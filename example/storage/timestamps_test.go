@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type (
+	stampedUser struct {
+		ID        UserID
+		CreatedAt time.Time
+		UpdatedAt time.Time
+	}
+	stampedUserSerializer struct{}
+)
+
+func (u stampedUser) Identifier() UserID {
+	return u.ID
+}
+
+func (u stampedUser) SetCreatedAt(t time.Time) stampedUser {
+	u.CreatedAt = t
+	return u
+}
+
+func (u stampedUser) SetUpdatedAt(t time.Time) stampedUser {
+	u.UpdatedAt = t
+	return u
+}
+
+func (s stampedUserSerializer) Serialize(u stampedUser) ([]byte, error) {
+	return json.Marshal(u)
+}
+
+func (s stampedUserSerializer) UnSerialize(bytes []byte) (stampedUser, error) {
+	var user stampedUser
+	err := json.Unmarshal(bytes, &user)
+	return user, err
+}
+
+func TestTimestamps_Set(t *testing.T) {
+	t.Run("Should set creation time once and update time on every Set", func(t *testing.T) {
+		ctx := context.Background()
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		repo := Timestamps[stampedUser, UserID]{
+			Next: NewInMemoryRepository[stampedUser, UserID](userIDSerializer{}, stampedUserSerializer{}),
+			Now: func() time.Time {
+				now = now.Add(time.Hour)
+				return now
+			},
+		}
+
+		_ = repo.Set(ctx, stampedUser{ID: "10"})
+		created, _ := repo.Get(ctx, "10")
+		if !created.CreatedAt.Equal(now) || !created.UpdatedAt.Equal(now) {
+			t.Fatalf("Expected creation and update time %s but got %v", now, created)
+		}
+
+		_ = repo.Set(ctx, created)
+		updated, _ := repo.Get(ctx, "10")
+		if !updated.CreatedAt.Equal(created.CreatedAt) {
+			t.Errorf("Got creation time %s but expected %s", updated.CreatedAt, created.CreatedAt)
+		}
+		if !updated.UpdatedAt.Equal(now) || updated.UpdatedAt.Equal(created.UpdatedAt) {
+			t.Errorf("Got update time %s but expected %s", updated.UpdatedAt, now)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// TombstoneStore keeps identifiers of deleted entities with deletion time.
+	TombstoneStore[K Identifier] interface {
+		Put(ctx context.Context, id K, at time.Time) error
+		// Since returns identifiers deleted at or after since, from the oldest deletion.
+		Since(since time.Time) ([]K, error)
+	}
+
+	// Tombstone records Deletes in Store before calling Next, so sync consumers can reconcile deletions.
+	// A Delete isn't attempted when its tombstone can't be written, so no deletion goes unnoticed.
+	Tombstone[T Entity[K], K Identifier] struct {
+		Next  Repository[T, K]
+		Store TombstoneStore[K]
+		now   func() time.Time
+	}
+
+	// InMemoryTombstones keeps the latest deletion time of every identifier in local memory.
+	InMemoryTombstones[K Identifier] struct {
+		lock    sync.Mutex
+		deleted map[K]time.Time
+	}
+)
+
+func NewTombstone[T Entity[K], K Identifier](next Repository[T, K], store TombstoneStore[K]) Tombstone[T, K] {
+	return Tombstone[T, K]{
+		Next:  next,
+		Store: store,
+		now:   time.Now,
+	}
+}
+
+func (t Tombstone[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return t.Next.Get(ctx, id)
+}
+
+func (t Tombstone[T, K]) Set(ctx context.Context, entity T) error {
+	return t.Next.Set(ctx, entity)
+}
+
+func (t Tombstone[T, K]) Delete(ctx context.Context, id K) error {
+	if err := t.Store.Put(ctx, id, t.now()); err != nil {
+		return fmt.Errorf("unable to write tombstone: %w", err)
+	}
+	return t.Next.Delete(ctx, id)
+}
+
+// Tombstones returns identifiers deleted at or after since.
+func (t Tombstone[T, K]) Tombstones(since time.Time) ([]K, error) {
+	return t.Store.Since(since)
+}
+
+func NewInMemoryTombstones[K Identifier]() *InMemoryTombstones[K] {
+	return &InMemoryTombstones[K]{deleted: make(map[K]time.Time)}
+}
+
+func (m *InMemoryTombstones[K]) Put(ctx context.Context, id K, at time.Time) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.deleted[id] = at
+	return nil
+}
+
+func (m *InMemoryTombstones[K]) Since(since time.Time) ([]K, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	var ids []K
+	for id, at := range m.deleted {
+		if !at.Before(since) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return m.deleted[ids[i]].Before(m.deleted[ids[j]])
+	})
+	return ids, nil
+}
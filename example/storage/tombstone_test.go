@@ -0,0 +1,61 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type failingTombstoneStore struct{}
+
+func (f failingTombstoneStore) Put(ctx context.Context, id UserID, at time.Time) error {
+	return errExample
+}
+
+func (f failingTombstoneStore) Since(since time.Time) ([]UserID, error) {
+	return nil, errExample
+}
+
+func TestTombstone(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Should record deletions queryable by time", func(t *testing.T) {
+		now := start
+		repo := NewTombstone[User, UserID](NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}), NewInMemoryTombstones[UserID]())
+		repo.now = func() time.Time {
+			now = now.Add(time.Hour)
+			return now
+		}
+		_ = repo.Set(ctx, User{ID: "10"})
+		for _, id := range []UserID{"10", "11", "12"} {
+			if err := repo.Delete(ctx, id); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+		}
+		if _, err := repo.Get(ctx, "10"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+
+		all, _ := repo.Tombstones(start)
+		if !reflect.DeepEqual(all, []UserID{"10", "11", "12"}) {
+			t.Errorf("Got %v but expected all deletions", all)
+		}
+		recent, _ := repo.Tombstones(start.Add(2 * time.Hour))
+		if !reflect.DeepEqual(recent, []UserID{"11", "12"}) {
+			t.Errorf("Got %v but expected deletions since second hour", recent)
+		}
+	})
+	t.Run("Should not delete without tombstone", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := NewTombstone[User, UserID](next, failingTombstoneStore{})
+		if err := repo.Delete(ctx, "10"); !errors.Is(err, errExample) {
+			t.Errorf("Expected example error but got: %v", err)
+		}
+		if calls := next.count("Delete"); calls != 0 {
+			t.Errorf("Got %d downstream Deletes but expected none", calls)
+		}
+	})
+}
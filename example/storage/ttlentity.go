@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type (
+	// Aged is implemented by entities knowing their creation time.
+	Aged interface {
+		CreatedAt() time.Time
+	}
+	AgedEntity[K Identifier] interface {
+		Entity[K]
+		Aged
+	}
+
+	// TTLEntity reports entities older than MaxAge as not found, for data retention.
+	// Expired entities are deleted when read if DeleteExpired is set, and kept in storage otherwise.
+	TTLEntity[T AgedEntity[K], K Identifier] struct {
+		Next          Repository[T, K]
+		MaxAge        time.Duration
+		DeleteExpired bool
+		// Now returns current time. Defaults to time.Now.
+		Now func() time.Time
+	}
+)
+
+func (t TTLEntity[T, K]) Get(ctx context.Context, id K) (T, error) {
+	entity, err := t.Next.Get(ctx, id)
+	if err != nil || t.now().Sub(entity.CreatedAt()) <= t.MaxAge {
+		return entity, err
+	}
+	var zero T
+	if t.DeleteExpired {
+		if err := t.Next.Delete(ctx, id); err != nil {
+			return zero, fmt.Errorf("unable to delete expired entity: %w", err)
+		}
+	}
+	return zero, errNotFound
+}
+
+func (t TTLEntity[T, K]) Set(ctx context.Context, entity T) error {
+	return t.Next.Set(ctx, entity)
+}
+
+func (t TTLEntity[T, K]) Delete(ctx context.Context, id K) error {
+	return t.Next.Delete(ctx, id)
+}
+
+func (t TTLEntity[T, K]) now() time.Time {
+	if t.Now == nil {
+		return time.Now()
+	}
+	return t.Now()
+}
@@ -0,0 +1,78 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type (
+	agedUser struct {
+		ID      UserID
+		Created time.Time
+	}
+	agedUserSerializer struct{}
+)
+
+func (u agedUser) Identifier() UserID {
+	return u.ID
+}
+
+func (u agedUser) CreatedAt() time.Time {
+	return u.Created
+}
+
+func (s agedUserSerializer) Serialize(u agedUser) ([]byte, error) {
+	return json.Marshal(u)
+}
+
+func (s agedUserSerializer) UnSerialize(bytes []byte) (agedUser, error) {
+	var user agedUser
+	err := json.Unmarshal(bytes, &user)
+	return user, err
+}
+
+func TestTTLEntity_Get(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+	newRepo := func(deleteExpired bool) (TTLEntity[agedUser, UserID], *InMemoryRepository[agedUser, UserID]) {
+		storage := NewInMemoryRepository[agedUser, UserID](userIDSerializer{}, agedUserSerializer{})
+		_ = storage.Set(ctx, agedUser{ID: "fresh", Created: now.Add(-24 * time.Hour)})
+		_ = storage.Set(ctx, agedUser{ID: "expired", Created: now.Add(-8 * 24 * time.Hour)})
+		return TTLEntity[agedUser, UserID]{
+			Next:          storage,
+			MaxAge:        7 * 24 * time.Hour,
+			DeleteExpired: deleteExpired,
+			Now: func() time.Time {
+				return now
+			},
+		}, storage
+	}
+
+	t.Run("Should return fresh entity", func(t *testing.T) {
+		repo, _ := newRepo(true)
+		if user, err := repo.Get(ctx, "fresh"); err != nil || user.ID != "fresh" {
+			t.Errorf("Got %v, %v but expected fresh entity", user, err)
+		}
+	})
+	t.Run("Should report expired entity as not found and delete it", func(t *testing.T) {
+		repo, storage := newRepo(true)
+		if _, err := repo.Get(ctx, "expired"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+		if _, err := storage.Get(ctx, "expired"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected expired entity deleted but got: %v", err)
+		}
+	})
+	t.Run("Should keep expired entity in storage unless deleting", func(t *testing.T) {
+		repo, storage := newRepo(false)
+		if _, err := repo.Get(ctx, "expired"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected not found error but got: %v", err)
+		}
+		if _, err := storage.Get(ctx, "expired"); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+}
@@ -0,0 +1,51 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// TypeRouter routes operations to repositories by entity variant, e.g. when T is an interface
+// with variants kept in different backends. Entities are routed by ByEntity and identifiers by ByIdentifier.
+type TypeRouter[T Entity[K], K Identifier] struct {
+	Routes       map[string]Repository[T, K]
+	ByEntity     func(T) string
+	ByIdentifier func(K) string
+}
+
+// ErrNoRoute is returned by TypeRouter for variants without a repository.
+var ErrNoRoute = errors.New("no repository for entity variant")
+
+func (r TypeRouter[T, K]) Get(ctx context.Context, id K) (T, error) {
+	repo, err := r.route(r.ByIdentifier(id))
+	if err != nil {
+		var entity T
+		return entity, err
+	}
+	return repo.Get(ctx, id)
+}
+
+func (r TypeRouter[T, K]) Set(ctx context.Context, entity T) error {
+	repo, err := r.route(r.ByEntity(entity))
+	if err != nil {
+		return err
+	}
+	return repo.Set(ctx, entity)
+}
+
+func (r TypeRouter[T, K]) Delete(ctx context.Context, id K) error {
+	repo, err := r.route(r.ByIdentifier(id))
+	if err != nil {
+		return err
+	}
+	return repo.Delete(ctx, id)
+}
+
+func (r TypeRouter[T, K]) route(variant string) (Repository[T, K], error) {
+	repo, exists := r.Routes[variant]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrNoRoute, variant)
+	}
+	return repo, nil
+}
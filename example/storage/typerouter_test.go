@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/jlisicki/middlewarebuilder"
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+type (
+	// document has invoice and receipt variants, identified with "inv-" and "rcp-" prefixes.
+	document interface {
+		Identifier() string
+	}
+	invoice struct {
+		ID     string
+		Amount int
+	}
+	receipt struct {
+		ID    string
+		Store string
+	}
+
+	invoiceSerializer  struct{}
+	receiptSerializer  struct{}
+	stringIDSerializer struct{}
+)
+
+func (i invoice) Identifier() string {
+	return i.ID
+}
+
+func (r receipt) Identifier() string {
+	return r.ID
+}
+
+func (s invoiceSerializer) Serialize(d document) ([]byte, error) {
+	return json.Marshal(d.(invoice))
+}
+
+func (s invoiceSerializer) UnSerialize(raw []byte) (document, error) {
+	var i invoice
+	err := json.Unmarshal(raw, &i)
+	return i, err
+}
+
+func (s receiptSerializer) Serialize(d document) ([]byte, error) {
+	return json.Marshal(d.(receipt))
+}
+
+func (s receiptSerializer) UnSerialize(raw []byte) (document, error) {
+	var r receipt
+	err := json.Unmarshal(raw, &r)
+	return r, err
+}
+
+func (s stringIDSerializer) Serialize(id string) ([]byte, error) {
+	return []byte(id), nil
+}
+
+func (s stringIDSerializer) UnSerialize(raw []byte) (string, error) {
+	return string(raw), nil
+}
+
+func TestTypeRouter(t *testing.T) {
+	ctx := context.Background()
+	invoices := NewInMemoryRepository[document, string](stringIDSerializer{}, invoiceSerializer{})
+	receipts := NewInMemoryRepository[document, string](stringIDSerializer{}, receiptSerializer{})
+	repo, err := middlewarebuilder.NewBuilder[Repository[document, string]]().
+		Add(middlewarebuilder.FactoryFunc[Repository[document, string]](func(next Repository[document, string]) (Repository[document, string], error) {
+			return Telemetry[document, string]{Next: next, Logger: log.New(io.Discard, "", 0)}, nil
+		})).
+		WithHandler(TypeRouter[document, string]{
+			Routes: map[string]Repository[document, string]{"invoice": invoices, "receipt": receipts},
+			ByEntity: func(d document) string {
+				switch d.(type) {
+				case invoice:
+					return "invoice"
+				case receipt:
+					return "receipt"
+				}
+				return ""
+			},
+			ByIdentifier: func(id string) string {
+				prefix, _, _ := strings.Cut(id, "-")
+				return map[string]string{"inv": "invoice", "rcp": "receipt"}[prefix]
+			},
+		}).
+		Build()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	t.Run("Should route variants to their repositories", func(t *testing.T) {
+		_ = repo.Set(ctx, invoice{ID: "inv-1", Amount: 100})
+		_ = repo.Set(ctx, receipt{ID: "rcp-1", Store: "corner"})
+
+		if d, err := repo.Get(ctx, "inv-1"); err != nil || d != (invoice{ID: "inv-1", Amount: 100}) {
+			t.Errorf("Got %v, %v but expected invoice", d, err)
+		}
+		if d, err := repo.Get(ctx, "rcp-1"); err != nil || d != (receipt{ID: "rcp-1", Store: "corner"}) {
+			t.Errorf("Got %v, %v but expected receipt", d, err)
+		}
+		if _, err := invoices.Get(ctx, "rcp-1"); !errors.Is(err, errNotFound) {
+			t.Errorf("Expected receipt not to be stored with invoices but got: %v", err)
+		}
+	})
+	t.Run("Should reject unknown variant", func(t *testing.T) {
+		if _, err := repo.Get(ctx, "ord-1"); !errors.Is(err, ErrNoRoute) {
+			t.Errorf("Expected no route error but got: %v", err)
+		}
+	})
+}
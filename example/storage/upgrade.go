@@ -0,0 +1,31 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+)
+
+// Upgrade brings written entities to the current schema, complementing Migrate which rewrites them on read.
+// Migrations are applied in order on every Set, so each must leave entities already in its target schema unchanged.
+type Upgrade[T Entity[K], K Identifier] struct {
+	Next       Repository[T, K]
+	Migrations []func(T) (T, error)
+}
+
+func (u Upgrade[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return u.Next.Get(ctx, id)
+}
+
+func (u Upgrade[T, K]) Set(ctx context.Context, entity T) error {
+	for i, migrate := range u.Migrations {
+		var err error
+		if entity, err = migrate(entity); err != nil {
+			return fmt.Errorf("unable to apply migration %d: %w", i, err)
+		}
+	}
+	return u.Next.Set(ctx, entity)
+}
+
+func (u Upgrade[T, K]) Delete(ctx context.Context, id K) error {
+	return u.Next.Delete(ctx, id)
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestUpgrade(t *testing.T) {
+	ctx := context.Background()
+	fillName := func(u User) (User, error) {
+		if u.Name == "" {
+			u.Name = "user " + string(u.ID)
+		}
+		return u, nil
+	}
+
+	t.Run("Should fill new field of outdated entity", func(t *testing.T) {
+		repo := Upgrade[User, UserID]{
+			Next:       NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}),
+			Migrations: []func(User) (User, error){fillName},
+		}
+		_ = repo.Set(ctx, User{ID: "10"})
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "user 10" {
+			t.Errorf("Got %v, %v but expected upgraded entity", user, err)
+		}
+	})
+	t.Run("Should leave current entity unchanged", func(t *testing.T) {
+		repo := Upgrade[User, UserID]{
+			Next:       NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{}),
+			Migrations: []func(User) (User, error){fillName, fillName},
+		}
+		_ = repo.Set(ctx, User{ID: "10", Name: "John"})
+		if user, err := repo.Get(ctx, "10"); err != nil || user.Name != "John" {
+			t.Errorf("Got %v, %v but expected John", user, err)
+		}
+	})
+	t.Run("Should not write when migration fails", func(t *testing.T) {
+		next := &stubRepository{}
+		repo := Upgrade[User, UserID]{
+			Next: next,
+			Migrations: []func(User) (User, error){func(u User) (User, error) {
+				return u, errExample
+			}},
+		}
+		if err := repo.Set(ctx, User{ID: "10"}); !errors.Is(err, errExample) {
+			t.Errorf("Expected example error but got: %v", err)
+		}
+		if calls := next.count("Set"); calls != 0 {
+			t.Errorf("Got %d downstream Sets but expected none", calls)
+		}
+	})
+}
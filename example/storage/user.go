@@ -46,17 +46,9 @@ func (u userSerializer) UnSerialize(bytes []byte) (User, error) {
 func NewUserRepository(debugWriter io.Writer) (UserRepository, error) {
 	builder := middlewarebuilder.NewBuilder[UserRepository]()
 	return builder.
-		Add(middlewarebuilder.FactoryFunc[UserRepository](func(next UserRepository) (UserRepository, error) {
-			return Telemetry[User, UserID]{Next: next}, nil
-		})).
-		Add(middlewarebuilder.FactoryFunc[UserRepository](func(next UserRepository) (UserRepository, error) {
-			return Debug[User, UserID]{Next: next, Output: debugWriter, Label: "CacheCall"}, nil
-		})).
-		Add(middlewarebuilder.FactoryFunc[UserRepository](func(next UserRepository) (UserRepository, error) {
-			return &Cache[User, UserID]{Next: next, cached: make(map[UserID]User)}, nil
-		})).
-		Add(middlewarebuilder.FactoryFunc[UserRepository](func(next UserRepository) (UserRepository, error) {
-			return Debug[User, UserID]{Next: next, Output: debugWriter, Label: "StorageCall"}, nil
-		})).
+		Add(TelemetryFactory[User, UserID](TelemetryOptions{})).
+		Add(DebugFactory[User, UserID](DebugOptions{Output: debugWriter, Label: "CacheCall"})).
+		Add(CacheFactory[User, UserID](CacheOptions{MaxEntries: 1000})).
+		Add(DebugFactory[User, UserID](DebugOptions{Output: debugWriter, Label: "StorageCall"})).
 		WithHandler(NewInMemoryRepository[User, UserID](userIDSerializer{}, userSerializer{})).Build()
 }
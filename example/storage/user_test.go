@@ -6,9 +6,9 @@ import (
 	"os"
 )
 
-// ExampleUserRepositoryGet presents usage of middlewares to inject debug middlewares
+// ExampleNewUserRepository presents usage of middlewares to inject debug middlewares
 // that allows to inspect cache and storage calls.
-func ExampleUserRepositoryGet() {
+func ExampleNewUserRepository() {
 	output := os.Stdout
 	repo, err := NewUserRepository(output)
 	if err != nil {
@@ -0,0 +1,29 @@
+package storage
+
+import "context"
+
+// WithValues injects a fixed set of context values before calling next repository,
+// e.g. to stamp a chain identity read by downstream logging.
+type WithValues[T Entity[K], K Identifier] struct {
+	Next   Repository[T, K]
+	Values map[any]any
+}
+
+func (w WithValues[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return w.Next.Get(w.context(ctx), id)
+}
+
+func (w WithValues[T, K]) Set(ctx context.Context, entity T) error {
+	return w.Next.Set(w.context(ctx), entity)
+}
+
+func (w WithValues[T, K]) Delete(ctx context.Context, id K) error {
+	return w.Next.Delete(w.context(ctx), id)
+}
+
+func (w WithValues[T, K]) context(ctx context.Context) context.Context {
+	for key, value := range w.Values {
+		ctx = context.WithValue(ctx, key, value)
+	}
+	return ctx
+}
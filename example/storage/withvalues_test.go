@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"context"
+	"testing"
+)
+
+type componentCtxKey string
+
+func TestWithValues(t *testing.T) {
+	t.Run("Should pass values to next repository", func(t *testing.T) {
+		var seen []any
+		next := &stubRepository{
+			getFunc: func(ctx context.Context, id UserID) (User, error) {
+				seen = append(seen, ctx.Value(componentCtxKey("component")))
+				return User{}, nil
+			},
+			setFunc: func(ctx context.Context, entity User) error {
+				seen = append(seen, ctx.Value(componentCtxKey("component")))
+				return nil
+			},
+			deleteFunc: func(ctx context.Context, id UserID) error {
+				seen = append(seen, ctx.Value(componentCtxKey("component")))
+				return nil
+			},
+		}
+		repo := WithValues[User, UserID]{Next: next, Values: map[any]any{componentCtxKey("component"): "users"}}
+		ctx := context.Background()
+		_, _ = repo.Get(ctx, "10")
+		_ = repo.Set(ctx, User{ID: "10"})
+		_ = repo.Delete(ctx, "10")
+
+		if len(seen) != 3 {
+			t.Fatalf("Got %d calls but expected 3", len(seen))
+		}
+		for i, value := range seen {
+			if value != "users" {
+				t.Errorf("Got '%v' in call %d but expected 'users'", value, i)
+			}
+		}
+	})
+}
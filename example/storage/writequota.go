@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// WriteQuota limits number of Set and Delete calls of every principal within a rolling window.
+// Writes without principal in context share the UnknownPrincipal quota.
+type WriteQuota[T Entity[K], K Identifier] struct {
+	Next   Repository[T, K]
+	Budget int
+	Window time.Duration
+	now    func() time.Time
+	lock   sync.Mutex
+	writes map[string][]time.Time
+	// sweepAt is number of tracked principals at which those with expired writes only are dropped.
+	sweepAt int
+}
+
+// ErrQuotaExceeded is returned for writes over principal's quota.
+var ErrQuotaExceeded = errors.New("write quota exceeded")
+
+func NewWriteQuota[T Entity[K], K Identifier](next Repository[T, K], budget int, window time.Duration) *WriteQuota[T, K] {
+	return &WriteQuota[T, K]{
+		Next:   next,
+		Budget: budget,
+		Window: window,
+		now:    time.Now,
+		writes: make(map[string][]time.Time),
+	}
+}
+
+func (w *WriteQuota[T, K]) Get(ctx context.Context, id K) (T, error) {
+	return w.Next.Get(ctx, id)
+}
+
+func (w *WriteQuota[T, K]) Set(ctx context.Context, entity T) error {
+	if err := w.take(ctx); err != nil {
+		return err
+	}
+	return w.Next.Set(ctx, entity)
+}
+
+func (w *WriteQuota[T, K]) Delete(ctx context.Context, id K) error {
+	if err := w.take(ctx); err != nil {
+		return err
+	}
+	return w.Next.Delete(ctx, id)
+}
+
+func (w *WriteQuota[T, K]) take(ctx context.Context) error {
+	principal, ok := principalFromContext(ctx)
+	if !ok {
+		principal = UnknownPrincipal
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	now := w.now()
+	writes := w.writes[principal]
+	for len(writes) > 0 && !writes[0].After(now.Add(-w.Window)) {
+		writes = writes[1:]
+	}
+	if len(writes) >= w.Budget {
+		if len(writes) == 0 {
+			delete(w.writes, principal)
+		} else {
+			w.writes[principal] = writes
+		}
+		return ErrQuotaExceeded
+	}
+	w.writes[principal] = append(writes, now)
+	w.sweep(now)
+	return nil
+}
+
+// sweep drops principals whose writes all expired, once their number doubled since the last sweep.
+func (w *WriteQuota[T, K]) sweep(now time.Time) {
+	if len(w.writes) < max(w.sweepAt, minSweepAt) {
+		return
+	}
+	for principal, writes := range w.writes {
+		if !writes[len(writes)-1].After(now.Add(-w.Window)) {
+			delete(w.writes, principal)
+		}
+	}
+	w.sweepAt = 2 * len(w.writes)
+}
@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestWriteQuota(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := NewWriteQuota[User, UserID](&stubRepository{}, 2, time.Minute)
+	repo.now = func() time.Time {
+		return now
+	}
+	alice := ContextWithPrincipal(context.Background(), "alice")
+	bob := ContextWithPrincipal(context.Background(), "bob")
+
+	t.Run("Should reject writes over quota", func(t *testing.T) {
+		_ = repo.Set(alice, User{ID: "10"})
+		_ = repo.Delete(alice, "10")
+		if err := repo.Set(alice, User{ID: "10"}); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("Expected quota exceeded error but got: %v", err)
+		}
+		if _, err := repo.Get(alice, "10"); errors.Is(err, ErrQuotaExceeded) {
+			t.Error("Unexpected quota of reads")
+		}
+	})
+	t.Run("Should allow writes of another principal", func(t *testing.T) {
+		if err := repo.Set(bob, User{ID: "11"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should share quota of writes without principal", func(t *testing.T) {
+		_ = repo.Delete(context.Background(), "10")
+		_ = repo.Delete(context.Background(), "10")
+		if err := repo.Delete(context.Background(), "10"); !errors.Is(err, ErrQuotaExceeded) {
+			t.Errorf("Expected quota exceeded error but got: %v", err)
+		}
+	})
+	t.Run("Should restore quota after window", func(t *testing.T) {
+		now = now.Add(time.Minute)
+		if err := repo.Set(alice, User{ID: "10"}); err != nil {
+			t.Errorf("Unexpected error: %s", err)
+		}
+	})
+	t.Run("Should drop principals with expired writes", func(t *testing.T) {
+		now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+		repo := NewWriteQuota[User, UserID](&stubRepository{}, 2, time.Minute)
+		repo.now = func() time.Time {
+			return now
+		}
+		for i := 0; i < 1000; i++ {
+			_ = repo.Set(ContextWithPrincipal(context.Background(), fmt.Sprint(i)), User{ID: "10"})
+			now = now.Add(time.Second)
+		}
+		if len(repo.writes) > 2*minSweepAt {
+			t.Errorf("Got %d tracked principals but expected at most %d", len(repo.writes), 2*minSweepAt)
+		}
+	})
+}
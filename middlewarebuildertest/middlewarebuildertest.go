@@ -0,0 +1,45 @@
+// Package middlewarebuildertest provides helpers for testing middleware chains.
+package middlewarebuildertest
+
+import (
+	"github.com/jlisicki/middlewarebuilder"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+// Spy records names of spy factories in order of their Create calls.
+// Factories are created from the handler outwards, so the order is reversed to the chain order.
+type Spy struct {
+	lock  sync.Mutex
+	calls []string
+}
+
+// AssertOrder reports test error unless names of builder's factories match expected order.
+func AssertOrder[T any](t testing.TB, b *middlewarebuilder.Builder[T], expected []string) {
+	t.Helper()
+	if names := b.Names(); !reflect.DeepEqual(names, expected) {
+		t.Errorf("Got middleware order %v but expected %v", names, expected)
+	}
+}
+
+// SpyFactory returns factory named name, which records its Create calls in spy and passes next unchanged.
+func SpyFactory[T any](spy *Spy, name string) middlewarebuilder.Factory[T] {
+	return middlewarebuilder.Named[T](name, middlewarebuilder.FactoryFunc[T](func(next T) (T, error) {
+		spy.record(name)
+		return next, nil
+	}))
+}
+
+// Calls returns names of spy factories in order of their Create calls.
+func (s *Spy) Calls() []string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return append([]string(nil), s.calls...)
+}
+
+func (s *Spy) record(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.calls = append(s.calls, name)
+}
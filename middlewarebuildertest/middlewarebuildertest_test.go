@@ -0,0 +1,62 @@
+package middlewarebuildertest
+
+import (
+	"fmt"
+	"github.com/jlisicki/middlewarebuilder"
+	"reflect"
+	"testing"
+)
+
+type recordingT struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+type handler struct{}
+
+func TestAssertOrder(t *testing.T) {
+	spy := &Spy{}
+	b := middlewarebuilder.NewBuilder[handler]().
+		Add(SpyFactory[handler](spy, "first")).
+		Add(SpyFactory[handler](spy, "second"))
+
+	t.Run("Should pass for expected order", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		AssertOrder(rt, b, []string{"first", "second"})
+		if len(rt.errors) != 0 {
+			t.Errorf("Unexpected errors: %v", rt.errors)
+		}
+	})
+	t.Run("Should fail for unexpected order", func(t *testing.T) {
+		rt := &recordingT{TB: t}
+		AssertOrder(rt, b, []string{"second", "first"})
+		if len(rt.errors) != 1 {
+			t.Errorf("Got %d errors but expected 1", len(rt.errors))
+		}
+	})
+}
+
+func TestSpyFactory(t *testing.T) {
+	t.Run("Should record create calls from handler outwards", func(t *testing.T) {
+		spy := &Spy{}
+		_, err := middlewarebuilder.NewBuilder[handler]().
+			Add(SpyFactory[handler](spy, "first")).
+			Add(SpyFactory[handler](spy, "second")).
+			Add(SpyFactory[handler](spy, "third")).
+			WithHandler(handler{}).
+			Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		expected := []string{"third", "second", "first"}
+		if calls := spy.Calls(); !reflect.DeepEqual(calls, expected) {
+			t.Errorf("Got %v but expected %v", calls, expected)
+		}
+	})
+}
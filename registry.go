@@ -0,0 +1,72 @@
+package middlewarebuilder
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	// Registry holds middleware factories by name, so chains can be assembled from configuration.
+	Registry[T any] struct {
+		constructors map[string]FactoryConstructor[T]
+	}
+
+	// FactoryConstructor creates middleware factory configured with options.
+	FactoryConstructor[T any] func(options map[string]string) (Factory[T], error)
+)
+
+var (
+	errUnknownFactory    = errors.New("unknown middleware factory")
+	errUnexpectedOptions = errors.New("middleware factory does not accept options")
+)
+
+func NewRegistry[T any]() *Registry[T] {
+	return &Registry[T]{constructors: make(map[string]FactoryConstructor[T])}
+}
+
+// Register middleware factory under a name. Registering the same name again replaces the factory.
+func (r *Registry[T]) Register(name string, f Factory[T]) *Registry[T] {
+	return r.RegisterConfigurable(name, func(options map[string]string) (Factory[T], error) {
+		if len(options) > 0 {
+			return nil, errUnexpectedOptions
+		}
+		return f, nil
+	})
+}
+
+// RegisterConfigurable registers constructor of middleware factory accepting options under a name.
+func (r *Registry[T]) RegisterConfigurable(name string, c FactoryConstructor[T]) *Registry[T] {
+	r.constructors[name] = c
+	return r
+}
+
+// Build adds factories registered under names to the builder in order. Added factories keep their names.
+// Nothing is added when any of the names is unknown.
+func (r *Registry[T]) Build(b *Builder[T], names []string) error {
+	config := ChainConfig{}
+	for _, name := range names {
+		config.Middlewares = append(config.Middlewares, MiddlewareConfig{Name: name})
+	}
+	return r.BuildConfig(b, config)
+}
+
+// BuildConfig adds factories described by config to the builder in order.
+// Nothing is added when any of the middlewares can't be created.
+func (r *Registry[T]) BuildConfig(b *Builder[T], config ChainConfig) error {
+	factories := make(Factories[T], 0, len(config.Middlewares))
+	for _, m := range config.Middlewares {
+		construct, exists := r.constructors[m.Name]
+		if !exists {
+			return fmt.Errorf("%w: %s", errUnknownFactory, m.Name)
+		}
+		f, err := construct(m.Options)
+		if err != nil {
+			return fmt.Errorf("unable to configure %s: %w", m.Name, err)
+		}
+		factories = append(factories, Named(m.Name, f))
+	}
+	for _, f := range factories {
+		b.Add(f)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package middlewarebuilder
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRegistry_Build(t *testing.T) {
+	registry := NewRegistry[textCreator]().
+		Register("first", exampleMiddlewareFactory{ExtraText: "first"}).
+		Register("second", exampleMiddlewareFactory{ExtraText: "second"})
+
+	t.Run("Should add registered factories in order of names", func(t *testing.T) {
+		b := NewBuilder[textCreator]().WithHandler(exampleHandler{})
+		if err := registry.Build(b, []string{"second", "first"}); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		chain, err := b.Build()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		out := chain.CreateText("input")
+		expected := "input: second: first: handler"
+		if out != expected {
+			t.Errorf("Got '%s' but expected '%s'", out, expected)
+		}
+	})
+	t.Run("Should return error for unknown name", func(t *testing.T) {
+		b := NewBuilder[textCreator]().WithHandler(exampleHandler{})
+		err := registry.Build(b, []string{"first", "unknown"})
+		if !errors.Is(err, errUnknownFactory) {
+			t.Errorf("Expected unknown factory error but got: %v", err)
+		}
+		if len(b.factories) != 0 {
+			t.Errorf("Expected no factories added but got %d", len(b.factories))
+		}
+	})
+}
@@ -0,0 +1,122 @@
+package middlewarebuilder
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+type (
+	// SingleFlight coalesces concurrent calls with equal keys into a single call sharing its result.
+	// It lets middlewares of any T deduplicate calls in flight, keyed by their arguments.
+	// Zero value is ready to use.
+	SingleFlight[V any] struct {
+		lock  sync.Mutex
+		calls map[string]*flight[V]
+	}
+
+	flight[V any] struct {
+		done    chan struct{}
+		value   V
+		err     error
+		callers int
+	}
+)
+
+// ErrFlightPanicked is returned to callers sharing a call which panicked. The panic is propagated to the caller making the call.
+var ErrFlightPanicked = errors.New("shared call panicked")
+
+// Do calls fn unless a call with key is in flight, in which case it waits for that call and returns its result.
+func (s *SingleFlight[V]) Do(key string, fn func() (V, error)) (V, error) {
+	s.lock.Lock()
+	if s.calls == nil {
+		s.calls = make(map[string]*flight[V])
+	}
+	if call, exists := s.calls[key]; exists {
+		call.callers++
+		s.lock.Unlock()
+		<-call.done
+		return call.value, call.err
+	}
+	call := &flight[V]{done: make(chan struct{}), callers: 1}
+	s.calls[key] = call
+	s.lock.Unlock()
+
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			call.err = fmt.Errorf("%w: %v", ErrFlightPanicked, recovered)
+		}
+		s.lock.Lock()
+		delete(s.calls, key)
+		s.lock.Unlock()
+		close(call.done)
+		if recovered != nil {
+			panic(recovered)
+		}
+	}()
+	call.value, call.err = fn()
+	return call.value, call.err
+}
+
+// DoContext is like Do, but fn runs with context detached from cancellation of callers, keeping deadline of the first one.
+// Each caller, including the first one, stops waiting when its own ctx is done, while the call completes for the others.
+// A panic of fn can't be propagated to a caller, it's returned as ErrFlightPanicked to all of them.
+func (s *SingleFlight[V]) DoContext(ctx context.Context, key string, fn func(ctx context.Context) (V, error)) (V, error) {
+	s.lock.Lock()
+	if s.calls == nil {
+		s.calls = make(map[string]*flight[V])
+	}
+	call, exists := s.calls[key]
+	if exists {
+		call.callers++
+	} else {
+		call = &flight[V]{done: make(chan struct{}), callers: 1}
+		s.calls[key] = call
+	}
+	s.lock.Unlock()
+
+	if !exists {
+		callCtx, cancel := detach(ctx)
+		go func() {
+			defer cancel()
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					call.err = fmt.Errorf("%w: %v", ErrFlightPanicked, recovered)
+				}
+				s.lock.Lock()
+				delete(s.calls, key)
+				s.lock.Unlock()
+				close(call.done)
+			}()
+			call.value, call.err = fn(callCtx)
+		}()
+	}
+	select {
+	case <-call.done:
+		return call.value, call.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// Callers returns number of callers sharing the call with key in flight, or zero when there's none.
+func (s *SingleFlight[V]) Callers(key string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if call, exists := s.calls[key]; exists {
+		return call.callers
+	}
+	return 0
+}
+
+// detach returns context not cancelled with ctx, but still bounded by its deadline.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(detached, deadline)
+	}
+	return context.WithCancel(detached)
+}
@@ -0,0 +1,144 @@
+package middlewarebuilder
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSingleFlight_Do(t *testing.T) {
+	t.Run("Should share single call between concurrent callers", func(t *testing.T) {
+		const callers = 10
+		var flights SingleFlight[string]
+		var calls atomic.Int32
+		release := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := flights.Do("key", func() (string, error) {
+					calls.Add(1)
+					<-release
+					return "value", errExample
+				})
+				if value != "value" || !errors.Is(err, errExample) {
+					t.Errorf("Got %s, %v but expected shared result", value, err)
+				}
+			}()
+		}
+		for flights.Callers("key") != callers {
+			runtime.Gosched()
+		}
+		close(release)
+		wg.Wait()
+		if got := calls.Load(); got != 1 {
+			t.Errorf("Got %d calls but expected 1", got)
+		}
+		if got := flights.Callers("key"); got != 0 {
+			t.Errorf("Got %d callers after the call but expected none", got)
+		}
+	})
+	t.Run("Should call again once previous call completed", func(t *testing.T) {
+		var flights SingleFlight[int]
+		for i := 1; i <= 2; i++ {
+			if value, _ := flights.Do("key", func() (int, error) { return i, nil }); value != i {
+				t.Errorf("Got %d but expected %d", value, i)
+			}
+		}
+	})
+	t.Run("Should report panic to callers sharing the call", func(t *testing.T) {
+		var flights SingleFlight[string]
+		started, release := make(chan struct{}), make(chan struct{})
+		panicked := make(chan any)
+		go func() {
+			defer func() {
+				panicked <- recover()
+			}()
+			_, _ = flights.Do("key", func() (string, error) {
+				close(started)
+				<-release
+				panic("broken")
+			})
+		}()
+		<-started
+		shared := make(chan error)
+		go func() {
+			_, err := flights.Do("key", func() (string, error) {
+				return "value", nil
+			})
+			shared <- err
+		}()
+		for flights.Callers("key") != 2 {
+			runtime.Gosched()
+		}
+		close(release)
+		if err := <-shared; !errors.Is(err, ErrFlightPanicked) {
+			t.Errorf("Expected flight panicked error but got: %v", err)
+		}
+		if recovered := <-panicked; recovered != "broken" {
+			t.Errorf("Got %v but expected panic propagated to the caller", recovered)
+		}
+	})
+}
+
+func TestSingleFlight_DoContext(t *testing.T) {
+	t.Run("Should complete call for others when caller gives up", func(t *testing.T) {
+		var flights SingleFlight[string]
+		started, release := make(chan struct{}), make(chan struct{})
+		first, cancel := context.WithCancel(context.Background())
+		firstErr := make(chan error)
+		go func() {
+			_, err := flights.DoContext(first, "key", func(ctx context.Context) (string, error) {
+				close(started)
+				<-release
+				return "value", ctx.Err()
+			})
+			firstErr <- err
+		}()
+		<-started
+		shared := make(chan string)
+		go func() {
+			value, _ := flights.DoContext(context.Background(), "key", func(ctx context.Context) (string, error) {
+				return "other", nil
+			})
+			shared <- value
+		}()
+		for flights.Callers("key") != 2 {
+			runtime.Gosched()
+		}
+		cancel()
+		if err := <-firstErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context cancelled error but got: %v", err)
+		}
+		close(release)
+		if value := <-shared; value != "value" {
+			t.Errorf("Got %s but expected result of shared call", value)
+		}
+	})
+	t.Run("Should keep deadline of first caller", func(t *testing.T) {
+		var flights SingleFlight[bool]
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		hasDeadline, _ := flights.DoContext(ctx, "key", func(ctx context.Context) (bool, error) {
+			_, ok := ctx.Deadline()
+			return ok, nil
+		})
+		if !hasDeadline {
+			t.Error("Expected shared call to have deadline")
+		}
+	})
+	t.Run("Should report panic to all callers", func(t *testing.T) {
+		var flights SingleFlight[string]
+		_, err := flights.DoContext(context.Background(), "key", func(ctx context.Context) (string, error) {
+			panic("broken")
+		})
+		if !errors.Is(err, ErrFlightPanicked) {
+			t.Errorf("Expected flight panicked error but got: %v", err)
+		}
+	})
+}